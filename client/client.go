@@ -3,6 +3,8 @@ package client
 import (
 	"fmt"
 	"io"
+	"sync"
+	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -17,14 +19,29 @@ import (
 type Client struct {
 	GClient pb.CorkTypeServiceClient
 	Conn    *grpc.ClientConn
+
+	// Signals received while a stage executes are sent to the running step
+	Signals <-chan os.Signal
+
+	// Records the stage's run if it is set
+	Recorder RunRecorder
 }
 
 type ParamProvider interface {
 	LoadParams(paramDefinitions map[string]*pb.ParamDefinition) (map[string]string, error)
 }
 
+// RunRecorder - Records the params a stage runs with and the events it streams
+type RunRecorder interface {
+	RecordParams(params map[string]string, paramDefinitions map[string]*pb.ParamDefinition)
+	RecordEvent(event *pb.ExecuteOutputEvent)
+}
+
 type StdinStreamer struct {
 	Stream pb.CorkTypeService_StageExecuteClient
+
+	// Input and signals are sent from different goroutines
+	lock sync.Mutex
 }
 
 func NewStreamer(stream pb.CorkTypeService_StageExecuteClient) *StdinStreamer {
@@ -34,7 +51,7 @@ func NewStreamer(stream pb.CorkTypeService_StageExecuteClient) *StdinStreamer {
 }
 
 func (s *StdinStreamer) Write(inputBytes []byte) (int, error) {
-	s.Stream.Send(&pb.ExecuteInputEvent{
+	s.send(&pb.ExecuteInputEvent{
 		Type: "input",
 		Body: &pb.ExecuteInputEvent_Input{
 			Input: &pb.InputEvent{
@@ -45,6 +62,71 @@ func (s *StdinStreamer) Write(inputBytes []byte) (int, error) {
 	return len(inputBytes), nil
 }
 
+// SendSignal - Sends a signal to the running step
+func (s *StdinStreamer) SendSignal(signal syscall.Signal) error {
+	return s.send(&pb.ExecuteInputEvent{
+		Type: "signal",
+		Body: &pb.ExecuteInputEvent_Signal{
+			Signal: &pb.SignalEvent{
+				Signal: int32(signal),
+			},
+		},
+	})
+}
+
+// SendResize - Sends the size of the terminal to the running step
+func (s *StdinStreamer) SendResize(rows int, cols int) error {
+	return s.send(&pb.ExecuteInputEvent{
+		Type: "resize",
+		Body: &pb.ExecuteInputEvent_Resize{
+			Resize: &pb.ResizeEvent{
+				Rows: uint32(rows),
+				Cols: uint32(cols),
+			},
+		},
+	})
+}
+
+// forwardTerminalSize - Sends the size of the terminal now and whenever it changes
+func (s *StdinStreamer) forwardTerminalSize(done chan bool) {
+	rows, cols, err := TerminalSize()
+	if err != nil {
+		log.Debugf("Not sending the terminal size: %v", err)
+		return
+	}
+	s.SendResize(rows, cols)
+	WatchTerminalSize(done, func(rows int, cols int) {
+		log.Debugf("Terminal resized to %dx%d", cols, rows)
+		s.SendResize(rows, cols)
+	})
+}
+
+func (s *StdinStreamer) send(event *pb.ExecuteInputEvent) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Stream.Send(event)
+}
+
+// forwardSignals - Sends signals to the running step until done is closed
+func (s *StdinStreamer) forwardSignals(signals <-chan os.Signal, done chan bool) {
+	for {
+		select {
+		case sig := <-signals:
+			unixSignal, ok := sig.(syscall.Signal)
+			if !ok {
+				continue
+			}
+			log.Debugf("Forwarding signal %v to the cork server", sig)
+			err := s.SendSignal(unixSignal)
+			if err != nil {
+				log.Debugf("Error forwarding signal %v: %v", sig, err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
 func New(serverAddress string) (*Client, error) {
 	connection, err := grpc.Dial(serverAddress, grpc.WithInsecure(), grpc.WithTimeout(5*time.Second))
 	if err != nil {
@@ -92,23 +174,107 @@ func (c *Client) Status() error {
 	return nil
 }
 
-func (c *Client) StageExecute(name string, paramProvider ParamProvider) (map[string]string, error) {
+// ListStages - Lists the stages of the cork type
+func (c *Client) ListStages() ([]*pb.StageSummary, error) {
+	res, err := c.GClient.ListStages(context.Background(), &pb.ListStagesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return res.GetStages(), nil
+}
+
+// ListParams - Gets the definitions of every param the cork type defines
+func (c *Client) ListParams() (map[string]*pb.ParamDefinition, error) {
+	res, err := c.GClient.ListParams(context.Background(), &pb.ListParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return res.GetParams(), nil
+}
+
+// DescribeStage - Gets the steps and params of a stage for the given tags
+func (c *Client) DescribeStage(name string, tags []string) (*pb.DescribeStageResponse, error) {
+	return c.GClient.DescribeStage(context.Background(), &pb.DescribeStageRequest{
+		Stage: name,
+		Tags:  tags,
+	})
+}
+
+// StageExecute - Runs a stage as requested and returns its exports
+func (c *Client) StageExecute(request *pb.StageExecuteRequestEvent, paramProvider ParamProvider) (map[string]string, error) {
 	stream, err := c.GClient.StageExecute(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
 	// Send initial message to start the stage
 	stream.Send(&pb.ExecuteInputEvent{
 		Type: "stageExecuteRequest",
 		Body: &pb.ExecuteInputEvent_StageExecuteRequest{
-			StageExecuteRequest: &pb.StageExecuteRequestEvent{
-				Stage: name,
-			},
+			StageExecuteRequest: request,
+		},
+	})
+
+	return c.executeSteps(stream, paramProvider)
+}
+
+// Initialize - Initializes a project with the type's init stage or hook. Returns
+// the params that were chosen for it. Sensitive params are left out so they
+// aren't saved with the project
+func (c *Client) Initialize(paramProvider ParamProvider) (map[string]string, error) {
+	stream, err := c.GClient.Initialize(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	stream.Send(&pb.ExecuteInputEvent{
+		Type: "initializeRequest",
+		Body: &pb.ExecuteInputEvent_InitializeRequest{
+			InitializeRequest: &pb.InitializeRequest{},
 		},
 	})
 
+	chosenParams := &chosenParamProvider{
+		ParamProvider: paramProvider,
+		Params:        map[string]string{},
+	}
+	_, err = c.executeSteps(stream, chosenParams)
 	if err != nil {
 		return nil, err
 	}
+	return chosenParams.Params, nil
+}
+
+// chosenParamProvider - Keeps the params that were provided that aren't sensitive
+type chosenParamProvider struct {
+	ParamProvider
+	Params map[string]string
+}
+
+func (p *chosenParamProvider) LoadParams(paramDefinitions map[string]*pb.ParamDefinition) (map[string]string, error) {
+	params, err := p.ParamProvider.LoadParams(paramDefinitions)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range params {
+		if !paramDefinitions[name].GetIsSensitive() {
+			p.Params[name] = value
+		}
+	}
+	return params, nil
+}
+
+// executeSteps - Streams the steps that a request runs. Returns their exports
+func (c *Client) executeSteps(stream pb.CorkTypeService_StageExecuteClient, paramProvider ParamProvider) (map[string]string, error) {
 	streamer := NewStreamer(stream)
+	demuxer := NewStepOutputDemuxer(os.Stdout)
+	defer demuxer.Flush()
+
+	done := make(chan bool)
+	defer close(done)
+
+	// Runs on return or panic once stdin is put in raw mode
+	defer RestoreTerminal()
 
 	exports := make(map[string]string)
 
@@ -120,19 +286,43 @@ func (c *Client) StageExecute(name string, paramProvider ParamProvider) (map[str
 		}
 		if event != nil {
 			log.Debugf("Receieved event type: %s", event.Type)
-			if event.Type == "end" {
-				break
+			if c.Recorder != nil {
+				c.Recorder.RecordEvent(event)
 			}
 			switch event.Type {
+			case "end":
+				end := event.GetEnd()
+				log.Debugf("Stage %s in %dms", end.GetStatus(), end.GetDurationMs())
+				if end.GetStatus() == "failed" {
+					return nil, fmt.Errorf("%s", end.GetError())
+				}
+				return exports, nil
+			case "stepStarted":
+				started := event.GetStepStarted()
+				log.Debugf("Step %d %s started (attempt %d)", started.GetIndex(), started.GetName(), started.GetAttempt())
+			case "stepFinished":
+				finished := event.GetStepFinished()
+				log.Debugf("Step %d %s %s in %dms", finished.GetIndex(), finished.GetName(), finished.GetStatus(), finished.GetDurationMs())
 			case "output":
 				switch body := event.GetBody().(type) {
 				case *pb.ExecuteOutputEvent_Output:
+					if body.Output.Step != "" {
+						err = demuxer.Write(body.Output.Step, body.Output.Bytes)
+						if err != nil {
+							return nil, err
+						}
+						break
+					}
+					err = demuxer.Flush()
+					if err != nil {
+						return nil, err
+					}
 					_, err = os.Stdout.Write(body.Output.Bytes)
 					if err != nil {
 						return nil, err
 					}
 				case *pb.ExecuteOutputEvent_Empty:
-					log.Debug("Got empty from: %s", event.Type)
+					log.Debugf("Got empty from: %s", event.Type)
 				}
 			case "paramsRequest":
 				paramsRequest := event.GetBody().(*pb.ExecuteOutputEvent_ParamsRequest)
@@ -140,7 +330,10 @@ func (c *Client) StageExecute(name string, paramProvider ParamProvider) (map[str
 				if err != nil {
 					return nil, err
 				}
-				stream.Send(&pb.ExecuteInputEvent{
+				if c.Recorder != nil {
+					c.Recorder.RecordParams(params, paramsRequest.ParamsRequest.ParamDefinitions)
+				}
+				streamer.send(&pb.ExecuteInputEvent{
 					Type: "paramsResponse",
 					Body: &pb.ExecuteInputEvent_ParamsResponse{
 						ParamsResponse: &pb.ParamsResponseEvent{
@@ -149,10 +342,18 @@ func (c *Client) StageExecute(name string, paramProvider ParamProvider) (map[str
 					},
 				})
 
+				// Params are prompted for first so stdin only becomes raw now
+				err = MakeStdinRaw()
+				if err != nil {
+					log.Debugf("Could not put stdin in raw mode: %v", err)
+				}
+				streamer.forwardTerminalSize(done)
 				go io.Copy(streamer, os.Stdin)
+				if c.Signals != nil {
+					go streamer.forwardSignals(c.Signals, done)
+				}
 			case "error":
-				errMessage := event.GetBody().(*pb.ExecuteOutputEvent_Error).Error.GetMessage()
-				return nil, fmt.Errorf("%s", errMessage)
+				return nil, NewStageError(event.GetError())
 			case "export":
 				switch body := event.GetBody().(type) {
 				case *pb.ExecuteOutputEvent_Export:
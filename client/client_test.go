@@ -0,0 +1,74 @@
+package client_test
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/virtru/cork/client"
+	pb "github.com/virtru/cork/protocol"
+)
+
+type recordingStream struct {
+	pb.CorkTypeService_StageExecuteClient
+	events []*pb.ExecuteInputEvent
+}
+
+func (s *recordingStream) Send(event *pb.ExecuteInputEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestStdinStreamerSendsSignals(t *testing.T) {
+	stream := &recordingStream{}
+	streamer := client.NewStreamer(stream)
+
+	_, err := streamer.Write([]byte("y\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, streamer.SendSignal(syscall.SIGINT))
+
+	if assert.Len(t, stream.events, 2) {
+		assert.Equal(t, "input", stream.events[0].GetType())
+		assert.Equal(t, []byte("y\n"), stream.events[0].GetInput().GetBytes())
+		assert.Equal(t, "signal", stream.events[1].GetType())
+		assert.Equal(t, int32(syscall.SIGINT), stream.events[1].GetSignal().GetSignal())
+	}
+}
+
+func TestStdinStreamerSendsResizes(t *testing.T) {
+	stream := &recordingStream{}
+	streamer := client.NewStreamer(stream)
+
+	assert.NoError(t, streamer.SendResize(50, 132))
+	if assert.Len(t, stream.events, 1) {
+		assert.Equal(t, "resize", stream.events[0].GetType())
+		assert.Equal(t, uint32(50), stream.events[0].GetResize().GetRows())
+		assert.Equal(t, uint32(132), stream.events[0].GetResize().GetCols())
+	}
+}
+
+func TestRawStdinIsSkippedWithoutTerminal(t *testing.T) {
+	// Tests don't run with stdin attached to a terminal
+	assert.NoError(t, client.MakeStdinRaw())
+	assert.NoError(t, client.RestoreTerminal())
+	assert.NoError(t, client.RestoreTerminal())
+}
+
+func TestStageErrorNamesTheFailedStep(t *testing.T) {
+	stageErr := client.NewStageError(&pb.ErrorEvent{
+		Message:  "exit status 3",
+		Kind:     pb.ErrorKindStep,
+		Step:     "test",
+		ExitCode: 3,
+	})
+	assert.Equal(t, pb.ErrorKindStep, stageErr.Kind)
+	assert.Equal(t, 3, stageErr.ExitCode)
+	assert.Equal(t, `Step "test" failed: exit status 3`, stageErr.Error())
+
+	stageErr = client.NewStageError(&pb.ErrorEvent{
+		Message:  "docker went away",
+		ExitCode: -1,
+	})
+	assert.Equal(t, pb.ErrorKindInfrastructure, stageErr.Kind)
+	assert.Equal(t, "docker went away", stageErr.Error())
+}
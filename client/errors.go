@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+
+	pb "github.com/virtru/cork/protocol"
+)
+
+// StageError - The error that stopped a stage. Errors of a step that failed
+// include the step and its exit code, which is -1 if the step didn't exit
+type StageError struct {
+	Message  string
+	Kind     string
+	Step     string
+	ExitCode int
+}
+
+func NewStageError(errorEvent *pb.ErrorEvent) StageError {
+	kind := errorEvent.GetKind()
+	if kind == "" {
+		kind = pb.ErrorKindInfrastructure
+	}
+	return StageError{
+		Message:  errorEvent.GetMessage(),
+		Kind:     kind,
+		Step:     errorEvent.GetStep(),
+		ExitCode: int(errorEvent.GetExitCode()),
+	}
+}
+
+func (se StageError) Error() string {
+	if se.Step != "" {
+		return fmt.Sprintf(`Step "%s" failed: %s`, se.Step, se.Message)
+	}
+	return se.Message
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// StepOutputDemuxer - Writes output from steps running in parallel line by line,
+// prefixing each line with the name of the step that produced it
+type StepOutputDemuxer struct {
+	Writer  io.Writer
+	partial map[string][]byte
+	order   []string
+}
+
+func NewStepOutputDemuxer(writer io.Writer) *StepOutputDemuxer {
+	return &StepOutputDemuxer{
+		Writer:  writer,
+		partial: make(map[string][]byte),
+	}
+}
+
+// Write - Writes the complete lines of output for a step. Incomplete lines are
+// held until the rest of the line arrives or the demuxer is flushed
+func (d *StepOutputDemuxer) Write(step string, p []byte) error {
+	if _, ok := d.partial[step]; !ok {
+		d.order = append(d.order, step)
+	}
+	buffered := append(d.partial[step], p...)
+	for {
+		index := bytes.IndexByte(buffered, '\n')
+		if index < 0 {
+			break
+		}
+		err := d.writeLine(step, buffered[:index+1])
+		if err != nil {
+			return err
+		}
+		buffered = buffered[index+1:]
+	}
+	d.partial[step] = buffered
+	return nil
+}
+
+// Flush - Writes any incomplete lines
+func (d *StepOutputDemuxer) Flush() error {
+	for _, step := range d.order {
+		buffered := d.partial[step]
+		if len(buffered) > 0 {
+			err := d.writeLine(step, append(buffered, '\n'))
+			if err != nil {
+				return err
+			}
+		}
+		delete(d.partial, step)
+	}
+	d.order = nil
+	return nil
+}
+
+func (d *StepOutputDemuxer) writeLine(step string, line []byte) error {
+	_, err := fmt.Fprintf(d.Writer, "[%s] %s", step, line)
+	return err
+}
@@ -0,0 +1,21 @@
+package client_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/virtru/cork/client"
+)
+
+func TestStepOutputDemuxer(t *testing.T) {
+	var output bytes.Buffer
+	demuxer := client.NewStepOutputDemuxer(&output)
+
+	assert.NoError(t, demuxer.Write("lint", []byte("linting fi")))
+	assert.NoError(t, demuxer.Write("unit", []byte("running tests\n3 passed\n")))
+	assert.NoError(t, demuxer.Write("lint", []byte("les\ndone")))
+	assert.NoError(t, demuxer.Flush())
+
+	assert.Equal(t, "[unit] running tests\n[unit] 3 passed\n[lint] linting files\n[lint] done\n", output.String())
+}
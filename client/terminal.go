@@ -0,0 +1,97 @@
+package client
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/sys/unix"
+)
+
+// TerminalSize - Gets the rows and columns of the terminal on stdout
+func TerminalSize() (int, int, error) {
+	cols, rows, err := terminal.GetSize(int(os.Stdout.Fd()))
+	return rows, cols, err
+}
+
+// WatchTerminalSize - Calls resize with the new size whenever the terminal is
+// resized until done is closed
+func WatchTerminalSize(done <-chan bool, resize func(rows int, cols int)) {
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(winch)
+		for {
+			select {
+			case <-winch:
+				rows, cols, err := TerminalSize()
+				if err != nil {
+					continue
+				}
+				resize(rows, cols)
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// StdinIsTerminal - Checks if stdin is a terminal that can be prompted
+func StdinIsTerminal() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// The state of stdin before it was put in raw mode
+var rawStdin struct {
+	lock  sync.Mutex
+	state *terminal.State
+}
+
+// MakeStdinRaw - Puts stdin in raw mode, if it is a terminal, so keystrokes are
+// sent as they are typed and aren't echoed locally. Signal keys are left on so
+// Ctrl-C still interrupts the run. Use RestoreTerminal to undo it
+func MakeStdinRaw() error {
+	rawStdin.lock.Lock()
+	defer rawStdin.lock.Unlock()
+	fd := int(os.Stdin.Fd())
+	if rawStdin.state != nil || !terminal.IsTerminal(fd) {
+		return nil
+	}
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	if err = enableSignalKeys(fd); err != nil {
+		terminal.Restore(fd, state)
+		return err
+	}
+	rawStdin.state = state
+	return nil
+}
+
+// enableSignalKeys - Turns ISIG back on after MakeRaw cleared it so Ctrl-C raises
+// SIGINT here instead of being sent to the server as input, which drops it when
+// no step is reading
+func enableSignalKeys(fd int) error {
+	termios, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return err
+	}
+	termios.Lflag |= unix.ISIG
+	return unix.IoctlSetTermios(fd, ioctlWriteTermios, termios)
+}
+
+// RestoreTerminal - Takes stdin out of raw mode. It is safe to call this more than
+// once or when stdin isn't in raw mode
+func RestoreTerminal() error {
+	rawStdin.lock.Lock()
+	defer rawStdin.lock.Unlock()
+	if rawStdin.state == nil {
+		return nil
+	}
+	err := terminal.Restore(int(os.Stdin.Fd()), rawStdin.state)
+	rawStdin.state = nil
+	return err
+}
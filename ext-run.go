@@ -11,7 +11,7 @@ func init() {
 		Name:        "ext-run",
 		Description: "External run",
 		Action:      cmdExternalRun,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			cli.BoolFlag{
 				Name:   "force-pull-image",
 				Usage:  "Forces cork to pull the latest version of the cork container",
@@ -32,7 +32,12 @@ func init() {
 				Name:  "param, p",
 				Usage: "Set Paramater param_name=param_value",
 			},
-		},
+			cli.StringSliceFlag{
+				Name:  "tag, t",
+				Usage: "Activate a tag for the run. Steps are included or skipped based on their match_tags and skip_tags",
+			},
+			signalGracePeriodFlag,
+		}, paramFlags...),
 	}
 	registerCommand(command)
 }
@@ -58,7 +63,8 @@ func cmdExternalRun(c *cli.Context) error {
 		return err
 	}
 
-	err = executeCorkRun(c, corkDef, stageName)
+	ignoreProfile(c)
+	err = executeCorkRun(c, corkDef, stageName, "")
 	if err != nil {
 		return err
 	}
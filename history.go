@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/virtru/cork/client"
+	"github.com/virtru/cork/utils/runlog"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func init() {
+	registerCommand(cli.Command{
+		Name:        "history",
+		Description: "List the recorded runs of the project",
+		Action:      cmdHistory,
+	})
+
+	registerCommand(cli.Command{
+		Name:        "logs",
+		Usage:       "logs [run-id]",
+		Description: "Replay the output of a run. The latest run is replayed if no run is given",
+		Action:      cmdLogs,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "step",
+				Usage: "Only replay the output of a step",
+			},
+		},
+	})
+}
+
+// newRunStore - The records of the project's runs
+func newRunStore() *runlog.Store {
+	return runlog.NewStore(path.Join(corkMetadataDir, "runs"))
+}
+
+func cmdHistory(c *cli.Context) error {
+	runs, err := newRunStore().List()
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Println("No runs have been recorded")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "RUN\tSTAGE\tSTATUS\tSTARTED\tDURATION")
+	for _, run := range runs {
+		duration := "-"
+		if run.Duration() > 0 {
+			duration = run.Duration().Round(time.Second).String()
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", run.ID, run.Stage, run.Status, run.StartedAt.Local().Format("2006-01-02 15:04:05"), duration)
+	}
+	return writer.Flush()
+}
+
+func cmdLogs(c *cli.Context) error {
+	store := newRunStore()
+
+	var run *runlog.Run
+	var err error
+	runID := c.Args().Get(0)
+	if runID == "" {
+		run, err = store.Latest()
+	} else {
+		run, err = store.Load(runID)
+	}
+	if err != nil {
+		return err
+	}
+
+	outputs, err := store.ReadOutput(run.ID)
+	if err != nil {
+		return err
+	}
+
+	stepName := c.String("step")
+	if stepName != "" {
+		found := false
+		for _, output := range outputs {
+			if output.Step == stepName {
+				found = true
+				os.Stdout.Write(output.Bytes)
+			}
+		}
+		if !found && !hasStep(run, stepName) {
+			return fmt.Errorf(`Step "%s" did not run in run %s`, stepName, run.ID)
+		}
+		return nil
+	}
+
+	blue := color.New(color.FgBlue)
+	blue.Printf("Run: ")
+	fmt.Println(run.ID)
+	blue.Printf("Stage: ")
+	fmt.Println(run.Stage)
+	blue.Printf("Started: ")
+	fmt.Println(run.StartedAt.Local().Format(time.RFC1123))
+	blue.Printf("Status: ")
+	fmt.Println(run.Status)
+	fmt.Println("")
+
+	// Output of parallel steps is replayed like it was shown during the run
+	demuxer := client.NewStepOutputDemuxer(os.Stdout)
+	for _, output := range outputs {
+		if output.Parallel {
+			err = demuxer.Write(output.Step, output.Bytes)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err = demuxer.Flush()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(output.Bytes)
+		if err != nil {
+			return err
+		}
+	}
+	err = demuxer.Flush()
+	if err != nil {
+		return err
+	}
+
+	if run.Error != "" {
+		color.Red("\n%s", run.Error)
+	}
+	return nil
+}
+
+func hasStep(run *runlog.Run, stepName string) bool {
+	for _, step := range run.Steps {
+		if step.Name == stepName {
+			return true
+		}
+	}
+	return false
+}
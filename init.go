@@ -2,22 +2,39 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+	"github.com/virtru/cork/client"
 	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v2"
 )
 
+// The project definition written by cork init
+const corkYamlPath = "cork.yml"
+
 func init() {
 	command := cli.Command{
 		Name:        "init",
+		Usage:       "init <project-type>",
 		Description: "Initialize a project using a specific project type",
 		Action:      cmdInitialize,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			cli.BoolFlag{
-				Name:   "force-pull-image",
-				Usage:  "Forces cork to pull the latest version of the cork container",
-				EnvVar: "CORK_FORCE_PULL_IMAGE",
+				Name:  "force, f",
+				Usage: "Overwrite an existing cork.yml",
+			},
+			cli.StringFlag{
+				Name:  "name",
+				Usage: "The name of the project. Defaults to the name of the current directory",
+			},
+			cli.StringSliceFlag{
+				Name:  "param, p",
+				Usage: `Set Paramater "param_name=param_value"`,
 			},
-		},
+		}, append(paramFlags, queryFlags...)...),
 	}
 	registerCommand(command)
 }
@@ -29,7 +46,12 @@ func cmdInitialize(c *cli.Context) error {
 		return fmt.Errorf("Must specify corkType")
 	}
 
+	if _, err := os.Stat(corkYamlPath); err == nil && !c.Bool("force") {
+		return fmt.Errorf("%s already exists. Use --force to overwrite it", corkYamlPath)
+	}
+
 	corkDef := &CorkDefinition{
+		Name: c.String("name"),
 		Type: corkType,
 	}
 
@@ -37,7 +59,36 @@ func cmdInitialize(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	ignoreProfile(c)
+	provider, err := newParamProvider(c, corkDef, "")
+	if err != nil {
+		return err
+	}
+
+	var chosenParams map[string]string
+	err = runCorkType(c, corkDef, func(corkClient *client.Client) error {
+		var err error
+		chosenParams, err = corkClient.Initialize(provider)
+		return err
+	})
+	if err != nil {
+		color.Red("\nCork failed to initialize the project")
+		return err
+	}
+
+	// Only values chosen for the project are saved. Values from the environment
+	// or the secret store stay out of cork.yml
+	corkDef.Params = provider.ProvidedBy(chosenParams, paramSourceFlag, paramSourcePrompt, paramSourceDefault)
+	corkDefBytes, err := yaml.Marshal(corkDef)
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("Initialize does not yet do anything")
+	log.Debugf("Writing %s", corkYamlPath)
+	err = ioutil.WriteFile(corkYamlPath, corkDefBytes, 0644)
+	if err != nil {
+		return err
+	}
+	color.Green("\nInitialized %s project %s in %s", corkDef.Type, corkDef.Name, corkYamlPath)
 	return nil
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 
@@ -56,5 +57,10 @@ func registerCommand(cmd cli.Command) {
 
 func main() {
 	app := setupApp()
-	app.Run(os.Args)
+	// cli only sets the exit status of errors that carry one
+	err := app.Run(os.Args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitStatus(err))
+	}
 }
@@ -18,18 +18,29 @@ It has these top-level messages:
 	ParamsResponseEvent
 	StageExecuteRequestEvent
 	SignalEvent
+	ResizeEvent
 	InputEvent
 	ExecuteOutputEvent
 	ParamDefinition
 	ParamsRequestEvent
 	EndEvent
+	StepStartedEvent
+	StepFinishedEvent
 	ErrorEvent
 	ExportEvent
 	OutputEvent
 	StepExecuteRequest
 	Step
+	StepBranch
 	StepListResponse
 	VolumesToMountGetResponse
+	ListStagesRequest
+	StageSummary
+	ListStagesResponse
+	DescribeStageRequest
+	DescribeStageResponse
+	ListParamsRequest
+	ListParamsResponse
 	StageExecuteRequest
 	EventReactRequest
 */
@@ -255,6 +266,8 @@ type ExecuteInputEvent struct {
 	//	*ExecuteInputEvent_Signal
 	//	*ExecuteInputEvent_Input
 	//	*ExecuteInputEvent_ParamsResponse
+	//	*ExecuteInputEvent_Resize
+	//	*ExecuteInputEvent_InitializeRequest
 	Body isExecuteInputEvent_Body `protobuf_oneof:"body"`
 }
 
@@ -282,12 +295,20 @@ type ExecuteInputEvent_Input struct {
 type ExecuteInputEvent_ParamsResponse struct {
 	ParamsResponse *ParamsResponseEvent `protobuf:"bytes,6,opt,name=paramsResponse,oneof"`
 }
+type ExecuteInputEvent_Resize struct {
+	Resize *ResizeEvent `protobuf:"bytes,7,opt,name=resize,oneof"`
+}
+type ExecuteInputEvent_InitializeRequest struct {
+	InitializeRequest *InitializeRequest `protobuf:"bytes,8,opt,name=initializeRequest,oneof"`
+}
 
 func (*ExecuteInputEvent_Empty) isExecuteInputEvent_Body()               {}
 func (*ExecuteInputEvent_StageExecuteRequest) isExecuteInputEvent_Body() {}
 func (*ExecuteInputEvent_Signal) isExecuteInputEvent_Body()              {}
 func (*ExecuteInputEvent_Input) isExecuteInputEvent_Body()               {}
 func (*ExecuteInputEvent_ParamsResponse) isExecuteInputEvent_Body()      {}
+func (*ExecuteInputEvent_Resize) isExecuteInputEvent_Body()              {}
+func (*ExecuteInputEvent_InitializeRequest) isExecuteInputEvent_Body()   {}
 
 func (m *ExecuteInputEvent) GetBody() isExecuteInputEvent_Body {
 	if m != nil {
@@ -338,6 +359,20 @@ func (m *ExecuteInputEvent) GetParamsResponse() *ParamsResponseEvent {
 	return nil
 }
 
+func (m *ExecuteInputEvent) GetResize() *ResizeEvent {
+	if x, ok := m.GetBody().(*ExecuteInputEvent_Resize); ok {
+		return x.Resize
+	}
+	return nil
+}
+
+func (m *ExecuteInputEvent) GetInitializeRequest() *InitializeRequest {
+	if x, ok := m.GetBody().(*ExecuteInputEvent_InitializeRequest); ok {
+		return x.InitializeRequest
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*ExecuteInputEvent) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _ExecuteInputEvent_OneofMarshaler, _ExecuteInputEvent_OneofUnmarshaler, _ExecuteInputEvent_OneofSizer, []interface{}{
@@ -346,6 +381,8 @@ func (*ExecuteInputEvent) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buf
 		(*ExecuteInputEvent_Signal)(nil),
 		(*ExecuteInputEvent_Input)(nil),
 		(*ExecuteInputEvent_ParamsResponse)(nil),
+		(*ExecuteInputEvent_Resize)(nil),
+		(*ExecuteInputEvent_InitializeRequest)(nil),
 	}
 }
 
@@ -378,6 +415,16 @@ func _ExecuteInputEvent_OneofMarshaler(msg proto.Message, b *proto.Buffer) error
 		if err := b.EncodeMessage(x.ParamsResponse); err != nil {
 			return err
 		}
+	case *ExecuteInputEvent_Resize:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Resize); err != nil {
+			return err
+		}
+	case *ExecuteInputEvent_InitializeRequest:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.InitializeRequest); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("ExecuteInputEvent.Body has unexpected type %T", x)
@@ -428,6 +475,22 @@ func _ExecuteInputEvent_OneofUnmarshaler(msg proto.Message, tag, wire int, b *pr
 		err := b.DecodeMessage(msg)
 		m.Body = &ExecuteInputEvent_ParamsResponse{msg}
 		return true, err
+	case 7: // body.resize
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ResizeEvent)
+		err := b.DecodeMessage(msg)
+		m.Body = &ExecuteInputEvent_Resize{msg}
+		return true, err
+	case 8: // body.initializeRequest
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(InitializeRequest)
+		err := b.DecodeMessage(msg)
+		m.Body = &ExecuteInputEvent_InitializeRequest{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -462,6 +525,16 @@ func _ExecuteInputEvent_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(6<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *ExecuteInputEvent_Resize:
+		s := proto.Size(x.Resize)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ExecuteInputEvent_InitializeRequest:
+		s := proto.Size(x.InitializeRequest)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -486,7 +559,15 @@ func (m *ParamsResponseEvent) GetParams() map[string]string {
 }
 
 type StageExecuteRequestEvent struct {
-	Stage string `protobuf:"bytes,1,opt,name=stage" json:"stage,omitempty"`
+	Stage string   `protobuf:"bytes,1,opt,name=stage" json:"stage,omitempty"`
+	Tags  []string `protobuf:"bytes,2,rep,name=tags" json:"tags,omitempty"`
+	// Identifies the run. Outputs of the run are kept under this id
+	RunId string `protobuf:"bytes,3,opt,name=runId" json:"runId,omitempty"`
+	// Selects the steps to run. Steps that are not selected use the outputs
+	// of the latest run of the stage
+	From  string `protobuf:"bytes,4,opt,name=from" json:"from,omitempty"`
+	Until string `protobuf:"bytes,5,opt,name=until" json:"until,omitempty"`
+	Only  string `protobuf:"bytes,6,opt,name=only" json:"only,omitempty"`
 }
 
 func (m *StageExecuteRequestEvent) Reset()                    { *m = StageExecuteRequestEvent{} }
@@ -501,6 +582,41 @@ func (m *StageExecuteRequestEvent) GetStage() string {
 	return ""
 }
 
+func (m *StageExecuteRequestEvent) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *StageExecuteRequestEvent) GetRunId() string {
+	if m != nil {
+		return m.RunId
+	}
+	return ""
+}
+
+func (m *StageExecuteRequestEvent) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *StageExecuteRequestEvent) GetUntil() string {
+	if m != nil {
+		return m.Until
+	}
+	return ""
+}
+
+func (m *StageExecuteRequestEvent) GetOnly() string {
+	if m != nil {
+		return m.Only
+	}
+	return ""
+}
+
 type SignalEvent struct {
 	Signal int32 `protobuf:"varint,1,opt,name=signal" json:"signal,omitempty"`
 }
@@ -517,6 +633,30 @@ func (m *SignalEvent) GetSignal() int32 {
 	return 0
 }
 
+type ResizeEvent struct {
+	Rows uint32 `protobuf:"varint,1,opt,name=rows" json:"rows,omitempty"`
+	Cols uint32 `protobuf:"varint,2,opt,name=cols" json:"cols,omitempty"`
+}
+
+func (m *ResizeEvent) Reset()                    { *m = ResizeEvent{} }
+func (m *ResizeEvent) String() string            { return proto.CompactTextString(m) }
+func (*ResizeEvent) ProtoMessage()               {}
+func (*ResizeEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *ResizeEvent) GetRows() uint32 {
+	if m != nil {
+		return m.Rows
+	}
+	return 0
+}
+
+func (m *ResizeEvent) GetCols() uint32 {
+	if m != nil {
+		return m.Cols
+	}
+	return 0
+}
+
 type InputEvent struct {
 	Bytes []byte `protobuf:"bytes,1,opt,name=bytes,proto3" json:"bytes,omitempty"`
 }
@@ -524,7 +664,7 @@ type InputEvent struct {
 func (m *InputEvent) Reset()                    { *m = InputEvent{} }
 func (m *InputEvent) String() string            { return proto.CompactTextString(m) }
 func (*InputEvent) ProtoMessage()               {}
-func (*InputEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*InputEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *InputEvent) GetBytes() []byte {
 	if m != nil {
@@ -542,13 +682,15 @@ type ExecuteOutputEvent struct {
 	//	*ExecuteOutputEvent_Export
 	//	*ExecuteOutputEvent_Error
 	//	*ExecuteOutputEvent_ParamsRequest
+	//	*ExecuteOutputEvent_StepStarted
+	//	*ExecuteOutputEvent_StepFinished
 	Body isExecuteOutputEvent_Body `protobuf_oneof:"body"`
 }
 
 func (m *ExecuteOutputEvent) Reset()                    { *m = ExecuteOutputEvent{} }
 func (m *ExecuteOutputEvent) String() string            { return proto.CompactTextString(m) }
 func (*ExecuteOutputEvent) ProtoMessage()               {}
-func (*ExecuteOutputEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*ExecuteOutputEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 type isExecuteOutputEvent_Body interface {
 	isExecuteOutputEvent_Body()
@@ -572,6 +714,12 @@ type ExecuteOutputEvent_Error struct {
 type ExecuteOutputEvent_ParamsRequest struct {
 	ParamsRequest *ParamsRequestEvent `protobuf:"bytes,7,opt,name=paramsRequest,oneof"`
 }
+type ExecuteOutputEvent_StepStarted struct {
+	StepStarted *StepStartedEvent `protobuf:"bytes,8,opt,name=stepStarted,oneof"`
+}
+type ExecuteOutputEvent_StepFinished struct {
+	StepFinished *StepFinishedEvent `protobuf:"bytes,9,opt,name=stepFinished,oneof"`
+}
 
 func (*ExecuteOutputEvent_Empty) isExecuteOutputEvent_Body()         {}
 func (*ExecuteOutputEvent_End) isExecuteOutputEvent_Body()           {}
@@ -579,6 +727,8 @@ func (*ExecuteOutputEvent_Output) isExecuteOutputEvent_Body()        {}
 func (*ExecuteOutputEvent_Export) isExecuteOutputEvent_Body()        {}
 func (*ExecuteOutputEvent_Error) isExecuteOutputEvent_Body()         {}
 func (*ExecuteOutputEvent_ParamsRequest) isExecuteOutputEvent_Body() {}
+func (*ExecuteOutputEvent_StepStarted) isExecuteOutputEvent_Body()   {}
+func (*ExecuteOutputEvent_StepFinished) isExecuteOutputEvent_Body()  {}
 
 func (m *ExecuteOutputEvent) GetBody() isExecuteOutputEvent_Body {
 	if m != nil {
@@ -636,6 +786,20 @@ func (m *ExecuteOutputEvent) GetParamsRequest() *ParamsRequestEvent {
 	return nil
 }
 
+func (m *ExecuteOutputEvent) GetStepStarted() *StepStartedEvent {
+	if x, ok := m.GetBody().(*ExecuteOutputEvent_StepStarted); ok {
+		return x.StepStarted
+	}
+	return nil
+}
+
+func (m *ExecuteOutputEvent) GetStepFinished() *StepFinishedEvent {
+	if x, ok := m.GetBody().(*ExecuteOutputEvent_StepFinished); ok {
+		return x.StepFinished
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*ExecuteOutputEvent) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _ExecuteOutputEvent_OneofMarshaler, _ExecuteOutputEvent_OneofUnmarshaler, _ExecuteOutputEvent_OneofSizer, []interface{}{
@@ -645,6 +809,8 @@ func (*ExecuteOutputEvent) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Bu
 		(*ExecuteOutputEvent_Export)(nil),
 		(*ExecuteOutputEvent_Error)(nil),
 		(*ExecuteOutputEvent_ParamsRequest)(nil),
+		(*ExecuteOutputEvent_StepStarted)(nil),
+		(*ExecuteOutputEvent_StepFinished)(nil),
 	}
 }
 
@@ -682,6 +848,16 @@ func _ExecuteOutputEvent_OneofMarshaler(msg proto.Message, b *proto.Buffer) erro
 		if err := b.EncodeMessage(x.ParamsRequest); err != nil {
 			return err
 		}
+	case *ExecuteOutputEvent_StepStarted:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.StepStarted); err != nil {
+			return err
+		}
+	case *ExecuteOutputEvent_StepFinished:
+		b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.StepFinished); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("ExecuteOutputEvent.Body has unexpected type %T", x)
@@ -740,6 +916,22 @@ func _ExecuteOutputEvent_OneofUnmarshaler(msg proto.Message, tag, wire int, b *p
 		err := b.DecodeMessage(msg)
 		m.Body = &ExecuteOutputEvent_ParamsRequest{msg}
 		return true, err
+	case 8: // body.stepStarted
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(StepStartedEvent)
+		err := b.DecodeMessage(msg)
+		m.Body = &ExecuteOutputEvent_StepStarted{msg}
+		return true, err
+	case 9: // body.stepFinished
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(StepFinishedEvent)
+		err := b.DecodeMessage(msg)
+		m.Body = &ExecuteOutputEvent_StepFinished{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -779,6 +971,16 @@ func _ExecuteOutputEvent_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(7<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *ExecuteOutputEvent_StepStarted:
+		s := proto.Size(x.StepStarted)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ExecuteOutputEvent_StepFinished:
+		s := proto.Size(x.StepFinished)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -792,12 +994,16 @@ type ParamDefinition struct {
 	HasDefault  bool   `protobuf:"varint,3,opt,name=hasDefault" json:"hasDefault,omitempty"`
 	Description string `protobuf:"bytes,4,opt,name=description" json:"description,omitempty"`
 	IsSensitive bool   `protobuf:"varint,5,opt,name=isSensitive" json:"isSensitive,omitempty"`
+	// The allowed values of an enum param
+	Choices []string `protobuf:"bytes,6,rep,name=choices" json:"choices,omitempty"`
+	// A regular expression that string, int and list values must match
+	Pattern string `protobuf:"bytes,7,opt,name=pattern" json:"pattern,omitempty"`
 }
 
 func (m *ParamDefinition) Reset()                    { *m = ParamDefinition{} }
 func (m *ParamDefinition) String() string            { return proto.CompactTextString(m) }
 func (*ParamDefinition) ProtoMessage()               {}
-func (*ParamDefinition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*ParamDefinition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
 func (m *ParamDefinition) GetType() string {
 	if m != nil {
@@ -834,6 +1040,20 @@ func (m *ParamDefinition) GetIsSensitive() bool {
 	return false
 }
 
+func (m *ParamDefinition) GetChoices() []string {
+	if m != nil {
+		return m.Choices
+	}
+	return nil
+}
+
+func (m *ParamDefinition) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
 type ParamsRequestEvent struct {
 	ParamDefinitions map[string]*ParamDefinition `protobuf:"bytes,1,rep,name=paramDefinitions" json:"paramDefinitions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
@@ -841,7 +1061,7 @@ type ParamsRequestEvent struct {
 func (m *ParamsRequestEvent) Reset()                    { *m = ParamsRequestEvent{} }
 func (m *ParamsRequestEvent) String() string            { return proto.CompactTextString(m) }
 func (*ParamsRequestEvent) ProtoMessage()               {}
-func (*ParamsRequestEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*ParamsRequestEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 func (m *ParamsRequestEvent) GetParamDefinitions() map[string]*ParamDefinition {
 	if m != nil {
@@ -852,12 +1072,16 @@ func (m *ParamsRequestEvent) GetParamDefinitions() map[string]*ParamDefinition {
 
 type EndEvent struct {
 	Tags []string `protobuf:"bytes,1,rep,name=tags" json:"tags,omitempty"`
+	// "succeeded" or "failed"
+	Status     string `protobuf:"bytes,2,opt,name=status" json:"status,omitempty"`
+	Error      string `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+	DurationMs int64  `protobuf:"varint,4,opt,name=durationMs" json:"durationMs,omitempty"`
 }
 
 func (m *EndEvent) Reset()                    { *m = EndEvent{} }
 func (m *EndEvent) String() string            { return proto.CompactTextString(m) }
 func (*EndEvent) ProtoMessage()               {}
-func (*EndEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*EndEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 func (m *EndEvent) GetTags() []string {
 	if m != nil {
@@ -866,14 +1090,164 @@ func (m *EndEvent) GetTags() []string {
 	return nil
 }
 
+func (m *EndEvent) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *EndEvent) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *EndEvent) GetDurationMs() int64 {
+	if m != nil {
+		return m.DurationMs
+	}
+	return 0
+}
+
+type StepStartedEvent struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Type string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	// The position of the step in the run, starting at 1
+	Index   int32 `protobuf:"varint,3,opt,name=index" json:"index,omitempty"`
+	Attempt int32 `protobuf:"varint,4,opt,name=attempt" json:"attempt,omitempty"`
+}
+
+func (m *StepStartedEvent) Reset()                    { *m = StepStartedEvent{} }
+func (m *StepStartedEvent) String() string            { return proto.CompactTextString(m) }
+func (*StepStartedEvent) ProtoMessage()               {}
+func (*StepStartedEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *StepStartedEvent) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StepStartedEvent) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *StepStartedEvent) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *StepStartedEvent) GetAttempt() int32 {
+	if m != nil {
+		return m.Attempt
+	}
+	return 0
+}
+
+type StepFinishedEvent struct {
+	Name    string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Type    string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	Index   int32  `protobuf:"varint,3,opt,name=index" json:"index,omitempty"`
+	Attempt int32  `protobuf:"varint,4,opt,name=attempt" json:"attempt,omitempty"`
+	// "succeeded", "failed" or "skipped"
+	Status     string `protobuf:"bytes,5,opt,name=status" json:"status,omitempty"`
+	DurationMs int64  `protobuf:"varint,6,opt,name=durationMs" json:"durationMs,omitempty"`
+	// -1 if the step failed without exiting, e.g. it timed out
+	ExitCode int32             `protobuf:"varint,7,opt,name=exitCode" json:"exitCode,omitempty"`
+	Outputs  map[string]string `protobuf:"bytes,8,rep,name=outputs" json:"outputs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Error    string            `protobuf:"bytes,9,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *StepFinishedEvent) Reset()                    { *m = StepFinishedEvent{} }
+func (m *StepFinishedEvent) String() string            { return proto.CompactTextString(m) }
+func (*StepFinishedEvent) ProtoMessage()               {}
+func (*StepFinishedEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *StepFinishedEvent) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StepFinishedEvent) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *StepFinishedEvent) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *StepFinishedEvent) GetAttempt() int32 {
+	if m != nil {
+		return m.Attempt
+	}
+	return 0
+}
+
+func (m *StepFinishedEvent) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *StepFinishedEvent) GetDurationMs() int64 {
+	if m != nil {
+		return m.DurationMs
+	}
+	return 0
+}
+
+func (m *StepFinishedEvent) GetExitCode() int32 {
+	if m != nil {
+		return m.ExitCode
+	}
+	return 0
+}
+
+func (m *StepFinishedEvent) GetOutputs() map[string]string {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *StepFinishedEvent) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
 type ErrorEvent struct {
 	Message string `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
+	// The step that failed, if a step failed
+	Step string `protobuf:"bytes,2,opt,name=step" json:"step,omitempty"`
+	// The exit code of the failed step. -1 if it failed without exiting
+	ExitCode int32 `protobuf:"varint,3,opt,name=exitCode" json:"exitCode,omitempty"`
+	// "step", "definition" or "infrastructure"
+	Kind string `protobuf:"bytes,4,opt,name=kind" json:"kind,omitempty"`
 }
 
 func (m *ErrorEvent) Reset()                    { *m = ErrorEvent{} }
 func (m *ErrorEvent) String() string            { return proto.CompactTextString(m) }
 func (*ErrorEvent) ProtoMessage()               {}
-func (*ErrorEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*ErrorEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *ErrorEvent) GetMessage() string {
 	if m != nil {
@@ -882,6 +1256,27 @@ func (m *ErrorEvent) GetMessage() string {
 	return ""
 }
 
+func (m *ErrorEvent) GetStep() string {
+	if m != nil {
+		return m.Step
+	}
+	return ""
+}
+
+func (m *ErrorEvent) GetExitCode() int32 {
+	if m != nil {
+		return m.ExitCode
+	}
+	return 0
+}
+
+func (m *ErrorEvent) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
 type ExportEvent struct {
 	Name  string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Value string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
@@ -890,7 +1285,7 @@ type ExportEvent struct {
 func (m *ExportEvent) Reset()                    { *m = ExportEvent{} }
 func (m *ExportEvent) String() string            { return proto.CompactTextString(m) }
 func (*ExportEvent) ProtoMessage()               {}
-func (*ExportEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*ExportEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *ExportEvent) GetName() string {
 	if m != nil {
@@ -909,12 +1304,14 @@ func (m *ExportEvent) GetValue() string {
 type OutputEvent struct {
 	Bytes  []byte `protobuf:"bytes,1,opt,name=bytes,proto3" json:"bytes,omitempty"`
 	Stream string `protobuf:"bytes,2,opt,name=stream" json:"stream,omitempty"`
+	// The step that produced the output when steps run in parallel
+	Step string `protobuf:"bytes,3,opt,name=step" json:"step,omitempty"`
 }
 
 func (m *OutputEvent) Reset()                    { *m = OutputEvent{} }
 func (m *OutputEvent) String() string            { return proto.CompactTextString(m) }
 func (*OutputEvent) ProtoMessage()               {}
-func (*OutputEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*OutputEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *OutputEvent) GetBytes() []byte {
 	if m != nil {
@@ -930,6 +1327,13 @@ func (m *OutputEvent) GetStream() string {
 	return ""
 }
 
+func (m *OutputEvent) GetStep() string {
+	if m != nil {
+		return m.Step
+	}
+	return ""
+}
+
 type StepExecuteRequest struct {
 	StepName string `protobuf:"bytes,1,opt,name=stepName" json:"stepName,omitempty"`
 }
@@ -937,7 +1341,7 @@ type StepExecuteRequest struct {
 func (m *StepExecuteRequest) Reset()                    { *m = StepExecuteRequest{} }
 func (m *StepExecuteRequest) String() string            { return proto.CompactTextString(m) }
 func (*StepExecuteRequest) ProtoMessage()               {}
-func (*StepExecuteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*StepExecuteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
 func (m *StepExecuteRequest) GetStepName() string {
 	if m != nil {
@@ -948,12 +1352,17 @@ func (m *StepExecuteRequest) GetStepName() string {
 
 type Step struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Type string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	// The steps run by each branch of a parallel step
+	Branches []*StepBranch `protobuf:"bytes,3,rep,name=branches" json:"branches,omitempty"`
+	// The condition that decides if the step runs
+	When string `protobuf:"bytes,4,opt,name=when" json:"when,omitempty"`
 }
 
 func (m *Step) Reset()                    { *m = Step{} }
 func (m *Step) String() string            { return proto.CompactTextString(m) }
 func (*Step) ProtoMessage()               {}
-func (*Step) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*Step) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *Step) GetName() string {
 	if m != nil {
@@ -962,6 +1371,43 @@ func (m *Step) GetName() string {
 	return ""
 }
 
+func (m *Step) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Step) GetBranches() []*StepBranch {
+	if m != nil {
+		return m.Branches
+	}
+	return nil
+}
+
+func (m *Step) GetWhen() string {
+	if m != nil {
+		return m.When
+	}
+	return ""
+}
+
+type StepBranch struct {
+	Steps []*Step `protobuf:"bytes,1,rep,name=steps" json:"steps,omitempty"`
+}
+
+func (m *StepBranch) Reset()                    { *m = StepBranch{} }
+func (m *StepBranch) String() string            { return proto.CompactTextString(m) }
+func (*StepBranch) ProtoMessage()               {}
+func (*StepBranch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *StepBranch) GetSteps() []*Step {
+	if m != nil {
+		return m.Steps
+	}
+	return nil
+}
+
 type StepListResponse struct {
 	Step []*Step `protobuf:"bytes,1,rep,name=step" json:"step,omitempty"`
 }
@@ -969,7 +1415,7 @@ type StepListResponse struct {
 func (m *StepListResponse) Reset()                    { *m = StepListResponse{} }
 func (m *StepListResponse) String() string            { return proto.CompactTextString(m) }
 func (*StepListResponse) ProtoMessage()               {}
-func (*StepListResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*StepListResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func (m *StepListResponse) GetStep() []*Step {
 	if m != nil {
@@ -985,7 +1431,7 @@ type VolumesToMountGetResponse struct {
 func (m *VolumesToMountGetResponse) Reset()                    { *m = VolumesToMountGetResponse{} }
 func (m *VolumesToMountGetResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumesToMountGetResponse) ProtoMessage()               {}
-func (*VolumesToMountGetResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*VolumesToMountGetResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *VolumesToMountGetResponse) GetVolumes() []string {
 	if m != nil {
@@ -994,6 +1440,162 @@ func (m *VolumesToMountGetResponse) GetVolumes() []string {
 	return nil
 }
 
+type ListStagesRequest struct {
+}
+
+func (m *ListStagesRequest) Reset()                    { *m = ListStagesRequest{} }
+func (m *ListStagesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListStagesRequest) ProtoMessage()               {}
+func (*ListStagesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+type StageSummary struct {
+	Name        string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Description string `protobuf:"bytes,2,opt,name=description" json:"description,omitempty"`
+}
+
+func (m *StageSummary) Reset()                    { *m = StageSummary{} }
+func (m *StageSummary) String() string            { return proto.CompactTextString(m) }
+func (*StageSummary) ProtoMessage()               {}
+func (*StageSummary) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *StageSummary) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StageSummary) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+type ListStagesResponse struct {
+	Stages []*StageSummary `protobuf:"bytes,1,rep,name=stages" json:"stages,omitempty"`
+}
+
+func (m *ListStagesResponse) Reset()                    { *m = ListStagesResponse{} }
+func (m *ListStagesResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListStagesResponse) ProtoMessage()               {}
+func (*ListStagesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *ListStagesResponse) GetStages() []*StageSummary {
+	if m != nil {
+		return m.Stages
+	}
+	return nil
+}
+
+type DescribeStageRequest struct {
+	Stage string   `protobuf:"bytes,1,opt,name=stage" json:"stage,omitempty"`
+	Tags  []string `protobuf:"bytes,2,rep,name=tags" json:"tags,omitempty"`
+}
+
+func (m *DescribeStageRequest) Reset()                    { *m = DescribeStageRequest{} }
+func (m *DescribeStageRequest) String() string            { return proto.CompactTextString(m) }
+func (*DescribeStageRequest) ProtoMessage()               {}
+func (*DescribeStageRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *DescribeStageRequest) GetStage() string {
+	if m != nil {
+		return m.Stage
+	}
+	return ""
+}
+
+func (m *DescribeStageRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+type DescribeStageResponse struct {
+	Name        string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Description string `protobuf:"bytes,2,opt,name=description" json:"description,omitempty"`
+	// The resolved steps that run for the requested tags
+	Steps []*Step `protobuf:"bytes,3,rep,name=steps" json:"steps,omitempty"`
+	// The params the steps require
+	Params map[string]*ParamDefinition `protobuf:"bytes,4,rep,name=params" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The steps that run after a step fails
+	OnFailure []*Step `protobuf:"bytes,5,rep,name=onFailure" json:"onFailure,omitempty"`
+	// The steps that always run last
+	Finally []*Step `protobuf:"bytes,6,rep,name=finally" json:"finally,omitempty"`
+}
+
+func (m *DescribeStageResponse) Reset()                    { *m = DescribeStageResponse{} }
+func (m *DescribeStageResponse) String() string            { return proto.CompactTextString(m) }
+func (*DescribeStageResponse) ProtoMessage()               {}
+func (*DescribeStageResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *DescribeStageResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DescribeStageResponse) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *DescribeStageResponse) GetSteps() []*Step {
+	if m != nil {
+		return m.Steps
+	}
+	return nil
+}
+
+func (m *DescribeStageResponse) GetParams() map[string]*ParamDefinition {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
+func (m *DescribeStageResponse) GetOnFailure() []*Step {
+	if m != nil {
+		return m.OnFailure
+	}
+	return nil
+}
+
+func (m *DescribeStageResponse) GetFinally() []*Step {
+	if m != nil {
+		return m.Finally
+	}
+	return nil
+}
+
+type ListParamsRequest struct {
+}
+
+func (m *ListParamsRequest) Reset()                    { *m = ListParamsRequest{} }
+func (m *ListParamsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListParamsRequest) ProtoMessage()               {}
+func (*ListParamsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+type ListParamsResponse struct {
+	Params map[string]*ParamDefinition `protobuf:"bytes,1,rep,name=params" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *ListParamsResponse) Reset()                    { *m = ListParamsResponse{} }
+func (m *ListParamsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListParamsResponse) ProtoMessage()               {}
+func (*ListParamsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *ListParamsResponse) GetParams() map[string]*ParamDefinition {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
 type StageExecuteRequest struct {
 	Stage string `protobuf:"bytes,1,opt,name=stage" json:"stage,omitempty"`
 }
@@ -1001,7 +1603,7 @@ type StageExecuteRequest struct {
 func (m *StageExecuteRequest) Reset()                    { *m = StageExecuteRequest{} }
 func (m *StageExecuteRequest) String() string            { return proto.CompactTextString(m) }
 func (*StageExecuteRequest) ProtoMessage()               {}
-func (*StageExecuteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*StageExecuteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
 
 func (m *StageExecuteRequest) GetStage() string {
 	if m != nil {
@@ -1019,7 +1621,7 @@ type EventReactRequest struct {
 func (m *EventReactRequest) Reset()                    { *m = EventReactRequest{} }
 func (m *EventReactRequest) String() string            { return proto.CompactTextString(m) }
 func (*EventReactRequest) ProtoMessage()               {}
-func (*EventReactRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*EventReactRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
 func (m *EventReactRequest) GetProject() string {
 	if m != nil {
@@ -1052,18 +1654,29 @@ func init() {
 	proto.RegisterType((*ParamsResponseEvent)(nil), "ParamsResponseEvent")
 	proto.RegisterType((*StageExecuteRequestEvent)(nil), "StageExecuteRequestEvent")
 	proto.RegisterType((*SignalEvent)(nil), "SignalEvent")
+	proto.RegisterType((*ResizeEvent)(nil), "ResizeEvent")
 	proto.RegisterType((*InputEvent)(nil), "InputEvent")
 	proto.RegisterType((*ExecuteOutputEvent)(nil), "ExecuteOutputEvent")
 	proto.RegisterType((*ParamDefinition)(nil), "ParamDefinition")
 	proto.RegisterType((*ParamsRequestEvent)(nil), "ParamsRequestEvent")
 	proto.RegisterType((*EndEvent)(nil), "EndEvent")
+	proto.RegisterType((*StepStartedEvent)(nil), "StepStartedEvent")
+	proto.RegisterType((*StepFinishedEvent)(nil), "StepFinishedEvent")
 	proto.RegisterType((*ErrorEvent)(nil), "ErrorEvent")
 	proto.RegisterType((*ExportEvent)(nil), "ExportEvent")
 	proto.RegisterType((*OutputEvent)(nil), "OutputEvent")
 	proto.RegisterType((*StepExecuteRequest)(nil), "StepExecuteRequest")
 	proto.RegisterType((*Step)(nil), "Step")
+	proto.RegisterType((*StepBranch)(nil), "StepBranch")
 	proto.RegisterType((*StepListResponse)(nil), "StepListResponse")
 	proto.RegisterType((*VolumesToMountGetResponse)(nil), "VolumesToMountGetResponse")
+	proto.RegisterType((*ListStagesRequest)(nil), "ListStagesRequest")
+	proto.RegisterType((*StageSummary)(nil), "StageSummary")
+	proto.RegisterType((*ListStagesResponse)(nil), "ListStagesResponse")
+	proto.RegisterType((*DescribeStageRequest)(nil), "DescribeStageRequest")
+	proto.RegisterType((*DescribeStageResponse)(nil), "DescribeStageResponse")
+	proto.RegisterType((*ListParamsRequest)(nil), "ListParamsRequest")
+	proto.RegisterType((*ListParamsResponse)(nil), "ListParamsResponse")
 	proto.RegisterType((*StageExecuteRequest)(nil), "StageExecuteRequest")
 	proto.RegisterType((*EventReactRequest)(nil), "EventReactRequest")
 }
@@ -1087,6 +1700,15 @@ type CorkTypeServiceClient interface {
 	StageExecute(ctx context.Context, opts ...grpc.CallOption) (CorkTypeService_StageExecuteClient, error)
 	// React to an event
 	EventReact(ctx context.Context, in *EventReactRequest, opts ...grpc.CallOption) (*Response, error)
+	// Lists the stages of the cork type
+	ListStages(ctx context.Context, in *ListStagesRequest, opts ...grpc.CallOption) (*ListStagesResponse, error)
+	// Describes the steps and params of a stage
+	DescribeStage(ctx context.Context, in *DescribeStageRequest, opts ...grpc.CallOption) (*DescribeStageResponse, error)
+	// Initializes a project by running the type's init stage or hooks/init.
+	// The first input event must be an initializeRequest
+	Initialize(ctx context.Context, opts ...grpc.CallOption) (CorkTypeService_InitializeClient, error)
+	// Lists every param the cork type defines
+	ListParams(ctx context.Context, in *ListParamsRequest, opts ...grpc.CallOption) (*ListParamsResponse, error)
 }
 
 type corkTypeServiceClient struct {
@@ -1155,6 +1777,64 @@ func (c *corkTypeServiceClient) EventReact(ctx context.Context, in *EventReactRe
 	return out, nil
 }
 
+func (c *corkTypeServiceClient) ListStages(ctx context.Context, in *ListStagesRequest, opts ...grpc.CallOption) (*ListStagesResponse, error) {
+	out := new(ListStagesResponse)
+	err := grpc.Invoke(ctx, "/CorkTypeService/ListStages", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *corkTypeServiceClient) DescribeStage(ctx context.Context, in *DescribeStageRequest, opts ...grpc.CallOption) (*DescribeStageResponse, error) {
+	out := new(DescribeStageResponse)
+	err := grpc.Invoke(ctx, "/CorkTypeService/DescribeStage", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *corkTypeServiceClient) Initialize(ctx context.Context, opts ...grpc.CallOption) (CorkTypeService_InitializeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_CorkTypeService_serviceDesc.Streams[1], c.cc, "/CorkTypeService/Initialize", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &corkTypeServiceInitializeClient{stream}
+	return x, nil
+}
+
+type CorkTypeService_InitializeClient interface {
+	Send(*ExecuteInputEvent) error
+	Recv() (*ExecuteOutputEvent, error)
+	grpc.ClientStream
+}
+
+type corkTypeServiceInitializeClient struct {
+	grpc.ClientStream
+}
+
+func (x *corkTypeServiceInitializeClient) Send(m *ExecuteInputEvent) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *corkTypeServiceInitializeClient) Recv() (*ExecuteOutputEvent, error) {
+	m := new(ExecuteOutputEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *corkTypeServiceClient) ListParams(ctx context.Context, in *ListParamsRequest, opts ...grpc.CallOption) (*ListParamsResponse, error) {
+	out := new(ListParamsResponse)
+	err := grpc.Invoke(ctx, "/CorkTypeService/ListParams", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for CorkTypeService service
 
 type CorkTypeServiceServer interface {
@@ -1166,6 +1846,15 @@ type CorkTypeServiceServer interface {
 	StageExecute(CorkTypeService_StageExecuteServer) error
 	// React to an event
 	EventReact(context.Context, *EventReactRequest) (*Response, error)
+	// Lists the stages of the cork type
+	ListStages(context.Context, *ListStagesRequest) (*ListStagesResponse, error)
+	// Describes the steps and params of a stage
+	DescribeStage(context.Context, *DescribeStageRequest) (*DescribeStageResponse, error)
+	// Initializes a project by running the type's init stage or hooks/init.
+	// The first input event must be an initializeRequest
+	Initialize(CorkTypeService_InitializeServer) error
+	// Lists every param the cork type defines
+	ListParams(context.Context, *ListParamsRequest) (*ListParamsResponse, error)
 }
 
 func RegisterCorkTypeServiceServer(s *grpc.Server, srv CorkTypeServiceServer) {
@@ -1252,6 +1941,86 @@ func _CorkTypeService_EventReact_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CorkTypeService_ListStages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CorkTypeServiceServer).ListStages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CorkTypeService/ListStages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CorkTypeServiceServer).ListStages(ctx, req.(*ListStagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CorkTypeService_DescribeStage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeStageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CorkTypeServiceServer).DescribeStage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CorkTypeService/DescribeStage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CorkTypeServiceServer).DescribeStage(ctx, req.(*DescribeStageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CorkTypeService_Initialize_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CorkTypeServiceServer).Initialize(&corkTypeServiceInitializeServer{stream})
+}
+
+type CorkTypeService_InitializeServer interface {
+	Send(*ExecuteOutputEvent) error
+	Recv() (*ExecuteInputEvent, error)
+	grpc.ServerStream
+}
+
+type corkTypeServiceInitializeServer struct {
+	grpc.ServerStream
+}
+
+func (x *corkTypeServiceInitializeServer) Send(m *ExecuteOutputEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *corkTypeServiceInitializeServer) Recv() (*ExecuteInputEvent, error) {
+	m := new(ExecuteInputEvent)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _CorkTypeService_ListParams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListParamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CorkTypeServiceServer).ListParams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CorkTypeService/ListParams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CorkTypeServiceServer).ListParams(ctx, req.(*ListParamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _CorkTypeService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "CorkTypeService",
 	HandlerType: (*CorkTypeServiceServer)(nil),
@@ -1268,6 +2037,18 @@ var _CorkTypeService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "EventReact",
 			Handler:    _CorkTypeService_EventReact_Handler,
 		},
+		{
+			MethodName: "ListStages",
+			Handler:    _CorkTypeService_ListStages_Handler,
+		},
+		{
+			MethodName: "DescribeStage",
+			Handler:    _CorkTypeService_DescribeStage_Handler,
+		},
+		{
+			MethodName: "ListParams",
+			Handler:    _CorkTypeService_ListParams_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1276,6 +2057,12 @@ var _CorkTypeService_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Initialize",
+			Handler:       _CorkTypeService_Initialize_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "cork.proto",
 }
@@ -1283,62 +2070,98 @@ var _CorkTypeService_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("cork.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 911 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0x5f, 0x6f, 0xe3, 0x44,
-	0x10, 0xaf, 0x93, 0xd8, 0x49, 0xc6, 0xe9, 0xb5, 0xdd, 0x14, 0xe4, 0x5a, 0xa2, 0x84, 0x45, 0x57,
-	0xf5, 0x84, 0x6e, 0x55, 0x15, 0x01, 0xf7, 0x47, 0xf0, 0x70, 0x5c, 0xc4, 0x9d, 0xe0, 0x00, 0x39,
-	0x77, 0xbc, 0xbb, 0xc9, 0x5c, 0x31, 0x4d, 0x6c, 0xe3, 0x5d, 0x47, 0x17, 0x3e, 0x02, 0xdf, 0x02,
-	0xf1, 0x08, 0x1f, 0x80, 0x67, 0xde, 0xf8, 0x56, 0x68, 0xff, 0x39, 0x4e, 0xe2, 0x0a, 0xa1, 0x7b,
-	0xf3, 0xcc, 0xfc, 0x66, 0x76, 0xf7, 0x37, 0xbf, 0xdd, 0x31, 0xc0, 0x34, 0x2b, 0x6e, 0x58, 0x5e,
-	0x64, 0x22, 0xa3, 0x5d, 0x70, 0xc7, 0x8b, 0x5c, 0xac, 0xe8, 0x9f, 0x0e, 0xf4, 0x22, 0xe4, 0x79,
-	0x96, 0x72, 0x24, 0xef, 0x82, 0xc7, 0x45, 0x2c, 0x4a, 0x1e, 0x38, 0x23, 0xe7, 0x7c, 0x3f, 0x32,
-	0x16, 0x39, 0x05, 0x17, 0x25, 0x3a, 0x68, 0x8d, 0x9c, 0x73, 0xff, 0xd2, 0x63, 0x2a, 0xf7, 0xd9,
-	0x5e, 0xa4, 0xdd, 0xe4, 0x1e, 0xb8, 0x5c, 0x60, 0xce, 0x83, 0xb6, 0x8a, 0x1f, 0xb1, 0x89, 0xc0,
-	0xfc, 0x9b, 0x84, 0x0b, 0x5b, 0x59, 0x42, 0x15, 0x82, 0x7c, 0x0a, 0xdd, 0x65, 0x36, 0x2f, 0x17,
-	0xc8, 0x83, 0x8e, 0x02, 0x87, 0xec, 0x07, 0x6d, 0xbf, 0xcc, 0x5e, 0x64, 0x65, 0x2a, 0xbe, 0xc2,
-	0x7a, 0x96, 0x05, 0x3f, 0x71, 0xa1, 0x5d, 0x20, 0xa7, 0xfb, 0xe0, 0x7f, 0x9d, 0xcc, 0xe7, 0x11,
-	0xfe, 0x5c, 0x22, 0x17, 0x74, 0x08, 0x47, 0xcf, 0xd3, 0x44, 0x24, 0xf1, 0x3c, 0xf9, 0x05, 0xad,
-	0xf3, 0x00, 0xf6, 0x27, 0x6a, 0xdf, 0xd6, 0xf1, 0x47, 0x0b, 0x8e, 0xc6, 0x6f, 0x70, 0x5a, 0x0a,
-	0x7c, 0x9e, 0xe6, 0xa5, 0x18, 0x2f, 0x31, 0x15, 0x84, 0x40, 0x47, 0xac, 0x72, 0x54, 0x47, 0xed,
-	0x47, 0xea, 0xfb, 0x3f, 0x0f, 0xfa, 0x02, 0x86, 0x5c, 0xc4, 0xd7, 0x68, 0xaa, 0x99, 0x05, 0xcc,
-	0xb1, 0x4f, 0xd8, 0x64, 0x37, 0xa6, 0xd6, 0x7a, 0xb6, 0x17, 0x35, 0xe5, 0x91, 0x33, 0xf0, 0x78,
-	0x72, 0x9d, 0xc6, 0x73, 0xc3, 0xc5, 0x80, 0x4d, 0x94, 0x69, 0x93, 0x4c, 0x94, 0x7c, 0x08, 0x6e,
-	0x22, 0x37, 0x1e, 0xb8, 0x0a, 0xe6, 0xb3, 0xf5, 0x31, 0xe4, 0xde, 0x54, 0x8c, 0x7c, 0x01, 0x77,
-	0xf2, 0xb8, 0x88, 0x17, 0xdc, 0xd2, 0x17, 0x78, 0x0a, 0x7d, 0xcc, 0xbe, 0xdf, 0x70, 0xdb, 0xb4,
-	0x2d, 0xf4, 0x13, 0x0f, 0x3a, 0x57, 0xd9, 0x6c, 0x45, 0x7f, 0x75, 0x60, 0xd8, 0x90, 0x41, 0x1e,
-	0x80, 0xa7, 0x33, 0x02, 0x67, 0xd4, 0x3e, 0xf7, 0x2f, 0x47, 0x4d, 0x75, 0x8d, 0x6f, 0x9c, 0x8a,
-	0x62, 0x15, 0x19, 0x7c, 0xf8, 0x10, 0xfc, 0x9a, 0x9b, 0x1c, 0x42, 0xfb, 0x06, 0x57, 0x86, 0x77,
-	0xf9, 0x49, 0x8e, 0xc1, 0x5d, 0xc6, 0xf3, 0x12, 0x15, 0xed, 0xfd, 0x48, 0x1b, 0x8f, 0x5a, 0x0f,
-	0x1c, 0x7a, 0x01, 0xc1, 0x6d, 0xa4, 0xca, 0x2c, 0x45, 0xaa, 0xa9, 0xa4, 0x0d, 0x7a, 0x17, 0xfc,
-	0x1a, 0x89, 0x4a, 0xd2, 0x9a, 0x62, 0x89, 0x72, 0x2d, 0xa5, 0x94, 0x02, 0xd4, 0xb4, 0x70, 0x0c,
-	0xee, 0xd5, 0x4a, 0xa0, 0xd6, 0xfd, 0x20, 0xd2, 0x06, 0xfd, 0xad, 0x05, 0xc4, 0x2c, 0xfc, 0x5d,
-	0x29, 0xde, 0x4a, 0x38, 0xef, 0x41, 0x1b, 0xd3, 0x99, 0x11, 0x4a, 0x9f, 0x8d, 0xd3, 0x99, 0x6d,
-	0x83, 0xf4, 0x4b, 0x21, 0x64, 0x6a, 0x85, 0x4a, 0x08, 0xb5, 0x05, 0xa5, 0x10, 0x74, 0x54, 0xe2,
-	0xf0, 0x4d, 0x9e, 0x15, 0x56, 0x09, 0x03, 0x36, 0x56, 0x66, 0x85, 0xd3, 0x51, 0x29, 0x18, 0x2c,
-	0x8a, 0xac, 0x30, 0x12, 0xf0, 0xd9, 0x58, 0x5a, 0x95, 0x60, 0x54, 0x8c, 0x3c, 0x86, 0x7d, 0x2b,
-	0x01, 0x2d, 0xe3, 0xae, 0x02, 0x0f, 0xab, 0xbe, 0x6e, 0x08, 0x78, 0x13, 0x5b, 0xa9, 0xe5, 0x77,
-	0x07, 0x0e, 0x14, 0xfe, 0x29, 0xbe, 0x4e, 0xe4, 0x55, 0xcc, 0xd2, 0x46, 0x82, 0x02, 0xe8, 0xce,
-	0xf0, 0x75, 0x5c, 0xce, 0x85, 0x69, 0xb2, 0x35, 0xc9, 0x29, 0xc0, 0x8f, 0x31, 0x7f, 0x6a, 0x82,
-	0x92, 0xa1, 0x5e, 0x54, 0xf3, 0x90, 0x11, 0xf8, 0x33, 0xe4, 0xd3, 0x22, 0xc9, 0x65, 0x71, 0x45,
-	0x50, 0x3f, 0xaa, 0xbb, 0x24, 0x22, 0xe1, 0x13, 0x4c, 0x79, 0x22, 0x92, 0x25, 0x2a, 0x6a, 0x7a,
-	0x51, 0xdd, 0x45, 0xff, 0x76, 0x80, 0xec, 0x9e, 0x8a, 0xbc, 0x82, 0xc3, 0x7c, 0x73, 0xef, 0x56,
-	0xdc, 0xf7, 0x1a, 0x48, 0x60, 0x5b, 0xe7, 0x34, 0x2a, 0xdf, 0x29, 0x11, 0xbe, 0x82, 0x77, 0x1a,
-	0xa1, 0x0d, 0xca, 0x3f, 0xab, 0x2b, 0xdf, 0xbf, 0x3c, 0xdc, 0x5e, 0xa3, 0x7e, 0x17, 0x4e, 0xa1,
-	0x67, 0x75, 0xa3, 0x28, 0x8e, 0xaf, 0xf5, 0x6e, 0x25, 0xc5, 0xf1, 0x35, 0xa7, 0x67, 0x00, 0xeb,
-	0x36, 0x4b, 0xc2, 0x17, 0xc8, 0xf9, 0xfa, 0x7e, 0x58, 0x93, 0x7e, 0x06, 0x7e, 0x4d, 0x35, 0xb2,
-	0x54, 0x1a, 0x2f, 0xaa, 0x6e, 0xc9, 0xef, 0xe6, 0x0b, 0x49, 0x1f, 0x83, 0x5f, 0xbf, 0x07, 0x8d,
-	0x97, 0x46, 0xcf, 0x90, 0x02, 0xe3, 0x85, 0xc9, 0x35, 0x16, 0xbd, 0x00, 0x22, 0xa7, 0xc2, 0xd6,
-	0x0b, 0x18, 0x42, 0x4f, 0xce, 0x85, 0x6f, 0xd7, 0x1b, 0xa8, 0x6c, 0x1a, 0x42, 0x47, 0x66, 0x34,
-	0x6d, 0x90, 0xde, 0x87, 0xc3, 0xed, 0x19, 0x43, 0x4e, 0xa0, 0x23, 0x73, 0x4d, 0x07, 0x5d, 0x35,
-	0x84, 0x22, 0xe5, 0xa2, 0x9f, 0xc0, 0xc9, 0xad, 0x53, 0x46, 0x32, 0x65, 0x47, 0x92, 0xa6, 0xd3,
-	0x9a, 0xf4, 0x23, 0x18, 0x36, 0xbc, 0x3e, 0xb7, 0x3c, 0x3c, 0x7f, 0x39, 0x70, 0xa4, 0x88, 0x89,
-	0x30, 0x9e, 0x0a, 0x8b, 0x0d, 0xa0, 0x9b, 0x17, 0xd9, 0x4f, 0x38, 0x15, 0xb6, 0x0d, 0xc6, 0xac,
-	0x5a, 0xd8, 0x5a, 0xb7, 0x90, 0x3c, 0x84, 0xae, 0xbe, 0xe9, 0x72, 0x94, 0xca, 0x53, 0xbc, 0xcf,
-	0x76, 0x4a, 0x9a, 0xa7, 0xc1, 0xa8, 0xcf, 0xe2, 0xc3, 0x47, 0x30, 0xa8, 0x07, 0xfe, 0xcf, 0x2b,
-	0x7b, 0xf9, 0x8f, 0x03, 0x07, 0x5f, 0x66, 0xc5, 0xcd, 0xcb, 0x55, 0x8e, 0x13, 0x2c, 0x96, 0xc9,
-	0x14, 0xc9, 0x5d, 0xf0, 0xf4, 0x14, 0x25, 0x77, 0xd8, 0xc6, 0x38, 0x0d, 0xfb, 0xcc, 0x52, 0x47,
-	0xf7, 0xc8, 0x07, 0xd0, 0x91, 0x03, 0x99, 0x0c, 0x58, 0x6d, 0x2e, 0x6f, 0x42, 0x3e, 0x87, 0x41,
-	0x9d, 0x45, 0x42, 0xd8, 0xce, 0x30, 0x0e, 0x87, 0x6c, 0xf7, 0xa1, 0xa5, 0x7b, 0xe7, 0xce, 0x85,
-	0x43, 0xee, 0x03, 0xac, 0x39, 0x90, 0xc9, 0xdb, 0x84, 0x6c, 0xac, 0x76, 0xe5, 0xa9, 0x1f, 0x9c,
-	0x8f, 0xff, 0x0d, 0x00, 0x00, 0xff, 0xff, 0x14, 0x58, 0x47, 0x82, 0xee, 0x08, 0x00, 0x00,
+	// 1481 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0xdf, 0x72, 0xd3, 0x46,
+	0x17, 0x8f, 0x22, 0xcb, 0xb1, 0x8f, 0x6d, 0x48, 0xd6, 0x81, 0x11, 0xfe, 0xe6, 0x83, 0x7c, 0xcb,
+	0xc0, 0x17, 0xa6, 0x83, 0x86, 0x49, 0x87, 0xf2, 0x6f, 0xda, 0x61, 0x42, 0x4c, 0x61, 0x28, 0x85,
+	0x91, 0xa1, 0xf7, 0x8a, 0xbd, 0x49, 0x44, 0x6c, 0x49, 0xd5, 0xae, 0x42, 0xcc, 0x23, 0xf4, 0xb2,
+	0x37, 0xed, 0xf4, 0x19, 0xfa, 0x00, 0x6d, 0x2f, 0x7b, 0xdd, 0xfb, 0xbe, 0x4e, 0x67, 0x8f, 0x76,
+	0xe5, 0x95, 0x25, 0x06, 0x28, 0xed, 0xdd, 0x9e, 0xb3, 0x67, 0xf7, 0xfc, 0xff, 0xed, 0x91, 0x00,
+	0xc6, 0x71, 0x7a, 0xec, 0x25, 0x69, 0x2c, 0x62, 0xba, 0x06, 0xce, 0x70, 0x96, 0x88, 0x39, 0xfd,
+	0xd9, 0x82, 0x96, 0xcf, 0x78, 0x12, 0x47, 0x9c, 0x91, 0xf3, 0xd0, 0xe4, 0x22, 0x10, 0x19, 0x77,
+	0xad, 0x2d, 0x6b, 0xbb, 0xe7, 0x2b, 0x8a, 0x5c, 0x04, 0x87, 0x49, 0x69, 0x77, 0x75, 0xcb, 0xda,
+	0xee, 0xec, 0x34, 0x3d, 0x3c, 0xfb, 0x68, 0xc5, 0xcf, 0xd9, 0xe4, 0x1a, 0x38, 0x5c, 0xb0, 0x84,
+	0xbb, 0x36, 0xee, 0x6f, 0x78, 0x23, 0xc1, 0x92, 0xaf, 0x42, 0x2e, 0xf4, 0xcd, 0x52, 0x14, 0x25,
+	0xc8, 0x67, 0xb0, 0x76, 0x12, 0x4f, 0xb3, 0x19, 0xe3, 0x6e, 0x03, 0x85, 0x07, 0xde, 0x37, 0x39,
+	0xfd, 0x22, 0x7e, 0x1a, 0x67, 0x91, 0xf8, 0x92, 0x99, 0xa7, 0xb4, 0xf0, 0xae, 0x03, 0x76, 0xca,
+	0x38, 0xed, 0x41, 0xe7, 0x49, 0x38, 0x9d, 0xfa, 0xec, 0xdb, 0x8c, 0x71, 0x41, 0xfb, 0xb0, 0xf1,
+	0x38, 0x0a, 0x45, 0x18, 0x4c, 0xc3, 0x37, 0x4c, 0x33, 0xcf, 0x42, 0x6f, 0x84, 0x76, 0x6b, 0xc6,
+	0x0f, 0x36, 0x6c, 0x0c, 0x4f, 0xd9, 0x38, 0x13, 0xec, 0x71, 0x94, 0x64, 0x62, 0x78, 0xc2, 0x22,
+	0x41, 0x08, 0x34, 0xc4, 0x3c, 0x61, 0xe8, 0x6a, 0xdb, 0xc7, 0xf5, 0x3b, 0x1d, 0x7d, 0x0a, 0x7d,
+	0x2e, 0x82, 0x43, 0xa6, 0x6e, 0x53, 0x0a, 0x94, 0xdb, 0x17, 0xbc, 0x51, 0x75, 0x0f, 0x75, 0x3d,
+	0x5a, 0xf1, 0xeb, 0xce, 0x91, 0xab, 0xd0, 0xe4, 0xe1, 0x61, 0x14, 0x4c, 0x55, 0x2c, 0xba, 0xde,
+	0x08, 0x49, 0x7d, 0x48, 0xed, 0x92, 0xcb, 0xe0, 0x84, 0xd2, 0x70, 0xd7, 0x41, 0xb1, 0x8e, 0xb7,
+	0x70, 0x43, 0xda, 0x86, 0x7b, 0xe4, 0x0b, 0x38, 0x93, 0x04, 0x69, 0x30, 0xe3, 0x3a, 0x7c, 0x6e,
+	0x13, 0xa5, 0x37, 0xbd, 0xe7, 0x25, 0xb6, 0x3e, 0xb6, 0x24, 0x2d, 0x8d, 0x49, 0x19, 0x0f, 0xdf,
+	0x30, 0x77, 0x4d, 0x19, 0xe3, 0x23, 0x59, 0x18, 0x93, 0xef, 0x92, 0x5d, 0xd8, 0x08, 0x97, 0x63,
+	0xee, 0xb6, 0xf0, 0x08, 0xf1, 0x2a, 0xd9, 0x78, 0xb4, 0xe2, 0x57, 0xc5, 0x77, 0x9b, 0xd0, 0xd8,
+	0x8f, 0x27, 0x73, 0xfa, 0x9d, 0x05, 0xfd, 0x1a, 0xeb, 0xc8, 0x6d, 0x68, 0xe6, 0xd6, 0xb9, 0xd6,
+	0x96, 0xbd, 0xdd, 0xd9, 0xd9, 0xaa, 0xf3, 0x41, 0xf1, 0x86, 0x91, 0x48, 0xe7, 0xbe, 0x92, 0x1f,
+	0xdc, 0x81, 0x8e, 0xc1, 0x26, 0xeb, 0x60, 0x1f, 0xb3, 0xb9, 0xca, 0xb1, 0x5c, 0x92, 0x4d, 0x70,
+	0x4e, 0x82, 0x69, 0xc6, 0x30, 0xc5, 0x6d, 0x3f, 0x27, 0xee, 0xae, 0xde, 0xb6, 0xe8, 0x8f, 0x16,
+	0xb8, 0x6f, 0xcb, 0xa0, 0x3c, 0x86, 0x19, 0x54, 0x57, 0xe5, 0x04, 0xd6, 0x50, 0x70, 0xc8, 0xdd,
+	0xd5, 0x2d, 0x1b, 0x6b, 0x28, 0x38, 0xe4, 0x52, 0x32, 0xcd, 0xa2, 0xc7, 0x13, 0xac, 0x8a, 0xb6,
+	0x9f, 0x13, 0x52, 0xf2, 0x20, 0x8d, 0x67, 0x98, 0xe8, 0xb6, 0x8f, 0x6b, 0x29, 0x99, 0x45, 0x22,
+	0x9c, 0x62, 0x5a, 0xdb, 0x7e, 0x4e, 0x48, 0xc9, 0x38, 0x9a, 0xce, 0x31, 0x7b, 0x6d, 0x1f, 0xd7,
+	0xf4, 0x0a, 0x74, 0x8c, 0xca, 0xc0, 0x3e, 0x45, 0x12, 0xad, 0x71, 0x74, 0x9d, 0xd0, 0x9b, 0xd0,
+	0x31, 0x72, 0x26, 0x6f, 0x4a, 0xe3, 0xd7, 0xba, 0x99, 0x71, 0x2d, 0x79, 0xe3, 0x78, 0xca, 0xd1,
+	0xfb, 0x9e, 0x8f, 0x6b, 0x4a, 0x01, 0x8c, 0xbe, 0xd8, 0x04, 0x67, 0x7f, 0x2e, 0x58, 0x7e, 0xac,
+	0xeb, 0xe7, 0x04, 0xfd, 0xde, 0x06, 0xa2, 0xe2, 0xf2, 0x2c, 0x13, 0x1f, 0xd5, 0x44, 0xff, 0x05,
+	0x9b, 0x45, 0x13, 0xd5, 0x34, 0x6d, 0x6f, 0x18, 0x4d, 0x74, 0x89, 0x49, 0xbe, 0xac, 0xc3, 0x18,
+	0x35, 0x14, 0x4d, 0x61, 0x28, 0x94, 0x75, 0x98, 0xef, 0x4a, 0x39, 0x76, 0x9a, 0xc4, 0xa9, 0xee,
+	0x8a, 0xae, 0x37, 0x44, 0xb2, 0x90, 0xcb, 0x77, 0x65, 0xf3, 0xb0, 0x34, 0x8d, 0x53, 0xd5, 0x0e,
+	0x1d, 0x6f, 0x28, 0xa9, 0xa2, 0x79, 0x70, 0x8f, 0xdc, 0x83, 0x9e, 0x6e, 0x87, 0xbc, 0xa0, 0xf3,
+	0x1e, 0xe8, 0x7b, 0xcf, 0x4d, 0xae, 0x3e, 0x54, 0x96, 0x25, 0x37, 0xa1, 0xc3, 0x05, 0x4b, 0x46,
+	0x22, 0x48, 0x05, 0x9b, 0xb8, 0x2d, 0x03, 0x04, 0x15, 0x4f, 0x1f, 0x34, 0xe5, 0xc8, 0x6d, 0xe8,
+	0x4a, 0xf2, 0x61, 0x18, 0x85, 0xfc, 0x88, 0x4d, 0xdc, 0xb6, 0xea, 0xa1, 0x91, 0xc1, 0xd4, 0x07,
+	0x4b, 0x92, 0x45, 0xfb, 0xfc, 0x69, 0xc1, 0x59, 0x34, 0x70, 0x8f, 0x1d, 0x60, 0x93, 0xc5, 0x51,
+	0x6d, 0x46, 0x5c, 0x58, 0x9b, 0xb0, 0x83, 0x20, 0x9b, 0x0a, 0x55, 0xf5, 0x9a, 0x24, 0x17, 0x01,
+	0x8e, 0x02, 0xbe, 0xa7, 0x36, 0x65, 0x4a, 0x5a, 0xbe, 0xc1, 0x21, 0x5b, 0xd0, 0x99, 0x30, 0x3e,
+	0x4e, 0xc3, 0x44, 0x5e, 0xae, 0xaa, 0xd7, 0x64, 0x49, 0x89, 0x90, 0x8f, 0x58, 0xc4, 0x43, 0x11,
+	0x9e, 0x30, 0xcc, 0x45, 0xcb, 0x37, 0x59, 0x52, 0xfb, 0xf8, 0x28, 0x0e, 0xc7, 0x8c, 0xbb, 0x4d,
+	0xec, 0x13, 0x4d, 0xca, 0x9d, 0x24, 0x10, 0x82, 0xa5, 0x11, 0xc6, 0xbb, 0xed, 0x6b, 0x92, 0xfe,
+	0x6e, 0x01, 0xa9, 0x86, 0x9e, 0xbc, 0x84, 0xf5, 0xa4, 0xec, 0xaf, 0x46, 0x88, 0x6b, 0x35, 0x99,
+	0xf2, 0x96, 0x62, 0xa3, 0xa0, 0xa2, 0x72, 0xc5, 0xe0, 0x25, 0x9c, 0xab, 0x15, 0xad, 0x81, 0x8f,
+	0xab, 0x26, 0x7c, 0x74, 0x76, 0xd6, 0x97, 0x75, 0x98, 0x80, 0x32, 0x85, 0x96, 0x2e, 0xee, 0x02,
+	0x29, 0x2c, 0x03, 0x29, 0x16, 0xcf, 0x6d, 0x9e, 0x15, 0x45, 0xc9, 0x0e, 0xcc, 0x2b, 0x56, 0x21,
+	0x08, 0x12, 0x32, 0x55, 0x93, 0x2c, 0x0d, 0xa4, 0x92, 0xa7, 0xf9, 0xe3, 0x69, 0xfb, 0x06, 0x87,
+	0xbe, 0x82, 0xf5, 0xe5, 0x8a, 0x93, 0x5a, 0xa3, 0x60, 0x56, 0x14, 0x83, 0x5c, 0x17, 0x05, 0xb2,
+	0x6a, 0x14, 0xc8, 0xa6, 0x7c, 0x60, 0x26, 0xec, 0x14, 0x35, 0x3a, 0x7e, 0x4e, 0xc8, 0xf4, 0xc8,
+	0x74, 0xcc, 0x92, 0xbc, 0x15, 0x1d, 0x5f, 0x93, 0xf4, 0x8f, 0x55, 0xd8, 0xa8, 0x94, 0xe9, 0xbf,
+	0xa5, 0xcd, 0x88, 0x93, 0x53, 0x8a, 0x53, 0x39, 0x22, 0xcd, 0xe5, 0x88, 0x90, 0x01, 0xb4, 0xd8,
+	0x69, 0x28, 0x1e, 0xc4, 0x93, 0xfc, 0x4d, 0x73, 0xfc, 0x82, 0x26, 0x77, 0x60, 0x2d, 0xc7, 0x11,
+	0xee, 0xb6, 0xb0, 0x80, 0x2e, 0x55, 0xfb, 0x4e, 0x01, 0x8f, 0x2a, 0x1b, 0x2d, 0xbf, 0x48, 0x4f,
+	0xdb, 0x48, 0xcf, 0xe0, 0x2e, 0x74, 0x4d, 0xf1, 0x0f, 0x7a, 0x79, 0x5e, 0x01, 0x2c, 0x40, 0x49,
+	0x06, 0x62, 0xc6, 0x38, 0x5f, 0x3c, 0x36, 0x9a, 0x94, 0xc1, 0xe4, 0x82, 0x25, 0x3a, 0x98, 0x72,
+	0x5d, 0x72, 0xd2, 0x5e, 0x72, 0x92, 0x40, 0xe3, 0x38, 0x8c, 0x26, 0xfa, 0xd1, 0x91, 0x6b, 0x7a,
+	0x0b, 0x3a, 0x06, 0x4e, 0xd6, 0xe6, 0xac, 0xd6, 0x50, 0xfa, 0x0c, 0x3a, 0x26, 0xf2, 0xd7, 0x3e,
+	0x13, 0x79, 0xaa, 0x52, 0x16, 0xcc, 0x16, 0x25, 0x2d, 0xa9, 0xc2, 0x72, 0x7b, 0x61, 0x39, 0xbd,
+	0x01, 0x44, 0x86, 0x7c, 0x69, 0x26, 0x1a, 0x40, 0x4b, 0xee, 0x7e, 0xbd, 0x30, 0xaa, 0xa0, 0xe9,
+	0x31, 0x34, 0xe4, 0x89, 0xf7, 0x2e, 0xb4, 0xff, 0x43, 0x6b, 0x3f, 0x0d, 0xa2, 0xf1, 0x11, 0x93,
+	0xa3, 0xa9, 0x8d, 0xe8, 0x2f, 0x2f, 0xd8, 0x45, 0xa6, 0x5f, 0x6c, 0xca, 0xc3, 0xaf, 0x8f, 0x98,
+	0xc6, 0x37, 0x5c, 0xd3, 0x6b, 0x00, 0x0b, 0x59, 0xf2, 0x1f, 0x3d, 0xe2, 0xe6, 0x70, 0xe3, 0xe0,
+	0x3d, 0x6a, 0xa8, 0xa5, 0xd7, 0x61, 0x7d, 0x79, 0xe2, 0x25, 0x17, 0x94, 0xc7, 0x25, 0xf9, 0xdc,
+	0xf1, 0x9b, 0x70, 0xe1, 0xad, 0x33, 0xaf, 0xcc, 0xbe, 0x1e, 0x90, 0x73, 0xac, 0xd0, 0xa4, 0x1c,
+	0x76, 0xa5, 0x06, 0x1c, 0x51, 0x8a, 0xd9, 0x76, 0x0f, 0xba, 0xc8, 0x18, 0x65, 0xb3, 0x59, 0x90,
+	0xce, 0x6b, 0x43, 0xb3, 0x04, 0xe2, 0xab, 0x15, 0x10, 0xa7, 0xf7, 0x80, 0x98, 0x57, 0x2b, 0x53,
+	0xae, 0x60, 0xdf, 0x1d, 0x32, 0xed, 0x74, 0xcf, 0x33, 0x55, 0xf9, 0x6a, 0x93, 0xde, 0x87, 0xcd,
+	0x3d, 0xbc, 0x6b, 0x9f, 0xe1, 0xbe, 0xce, 0xe4, 0x7b, 0x8f, 0x4c, 0xf4, 0xd7, 0x55, 0x38, 0xb7,
+	0x74, 0x85, 0x32, 0xe1, 0x6f, 0xb9, 0xb3, 0x48, 0x96, 0x5d, 0x4d, 0x16, 0xb9, 0x5b, 0xcc, 0x96,
+	0x0d, 0xdc, 0xa5, 0x5e, 0xad, 0xea, 0xba, 0xe9, 0x92, 0x5c, 0x86, 0x76, 0x1c, 0x3d, 0x0c, 0xc2,
+	0x69, 0x96, 0xca, 0xa7, 0xce, 0xb8, 0x7c, 0xc1, 0x27, 0x97, 0x60, 0xed, 0x20, 0x8c, 0x82, 0x29,
+	0xce, 0x70, 0x86, 0x88, 0xe6, 0x0e, 0x9e, 0xbc, 0x6b, 0x46, 0xfd, 0x90, 0x47, 0x46, 0x55, 0x45,
+	0xe9, 0xf5, 0xa3, 0x3f, 0x59, 0x40, 0x4c, 0xae, 0x8a, 0xe6, 0xad, 0xa5, 0xb1, 0xfa, 0x92, 0x57,
+	0x15, 0xaa, 0x9d, 0xaa, 0xff, 0x51, 0x8b, 0x3f, 0x81, 0x7e, 0xcd, 0x98, 0x5d, 0x5f, 0x2e, 0xf4,
+	0x17, 0x0b, 0x36, 0x10, 0x70, 0x7c, 0x16, 0x8c, 0x85, 0x96, 0x95, 0x83, 0x43, 0x1a, 0xbf, 0x62,
+	0x63, 0xa1, 0x21, 0x52, 0x91, 0xb5, 0x13, 0xb9, 0x81, 0xf5, 0xb6, 0xf2, 0xbb, 0x72, 0x65, 0x3d,
+	0xd6, 0x7f, 0x0c, 0xaa, 0xef, 0xfc, 0x66, 0xc3, 0xd9, 0x07, 0x71, 0x7a, 0xfc, 0x62, 0x9e, 0xb0,
+	0x11, 0x4b, 0x4f, 0xc2, 0x31, 0xb6, 0x54, 0xfe, 0x6d, 0x4a, 0xce, 0x78, 0xa5, 0x8f, 0xd4, 0x41,
+	0xdb, 0xd3, 0x19, 0xa0, 0x2b, 0xe4, 0x7f, 0xd0, 0x90, 0x9f, 0xb9, 0xa4, 0xeb, 0x19, 0x5f, 0xbb,
+	0x65, 0x91, 0xcf, 0xa1, 0x6b, 0x46, 0x91, 0x10, 0xaf, 0xf2, 0x89, 0x3b, 0xe8, 0x7b, 0xd5, 0x91,
+	0x9d, 0xae, 0x6c, 0x5b, 0x37, 0x2c, 0x72, 0x1d, 0x60, 0x11, 0x03, 0x79, 0x78, 0x39, 0x20, 0x65,
+	0x6d, 0xb7, 0x00, 0x16, 0x00, 0x41, 0x88, 0x57, 0x01, 0xa2, 0x41, 0xdf, 0xab, 0x22, 0x08, 0x5d,
+	0x21, 0xf7, 0xa1, 0x57, 0x6a, 0x2f, 0x72, 0xce, 0xab, 0x03, 0x8b, 0xc1, 0xf9, 0xfa, 0x2e, 0xa4,
+	0x2b, 0xe4, 0x9e, 0xfc, 0x3a, 0xd1, 0x1f, 0x90, 0x1f, 0xea, 0xa6, 0xb2, 0x3b, 0x2f, 0x5e, 0x65,
+	0x77, 0xa9, 0x55, 0x06, 0xfd, 0x12, 0x4f, 0x6b, 0xdd, 0x6f, 0xe2, 0x7f, 0x92, 0x4f, 0xff, 0x1a,
+	0x00, 0x7d, 0xad, 0x8f, 0xb0, 0x35, 0x11, 0x00, 0x00,
 }
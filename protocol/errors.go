@@ -0,0 +1,13 @@
+package cork
+
+// The kinds of errors in an ErrorEvent
+const (
+	// A step failed
+	ErrorKindStep = "step"
+
+	// The definition or the request cannot run, e.g. a param is missing
+	ErrorKindDefinition = "definition"
+
+	// Anything else that stopped the stage
+	ErrorKindInfrastructure = "infrastructure"
+)
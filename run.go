@@ -8,23 +8,38 @@ import (
 	"os/signal"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
+	"github.com/virtru/cork/client"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/utils/params"
+	"github.com/virtru/cork/utils/runlog"
 
 	"github.com/fatih/color"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"gopkg.in/urfave/cli.v1"
 	"gopkg.in/yaml.v2"
 )
 
 // CorkDefinition - The Cork Definition file
 type CorkDefinition struct {
-	Name   string            `yaml:"name,omitempty"`
-	Type   string            `yaml:"type"`
-	Params map[string]string `yaml:"params,omitempty"`
+	Name     string                     `yaml:"name,omitempty"`
+	Type     string                     `yaml:"type"`
+	Params   map[string]string          `yaml:"params,omitempty"`
+	Profiles map[string]*params.Profile `yaml:"profiles,omitempty"`
+}
+
+// ProfileParams - Resolves the params of a profile, including the ones it inherits
+func (cd *CorkDefinition) ProfileParams(profileName string) (map[string]string, error) {
+	return params.ResolveProfile(cd.Profiles, profileName)
 }
 
 func (cd *CorkDefinition) LoadName() error {
@@ -39,6 +54,20 @@ func (cd *CorkDefinition) LoadName() error {
 	return nil
 }
 
+// parseParamFlags - Parses "param_name=param_value" flags
+func parseParamFlags(rawParams []string) map[string]string {
+	flagParams := make(map[string]string)
+	for _, rawParam := range rawParams {
+		splitParams := strings.Split(rawParam, "=")
+
+		paramName := splitParams[0]
+		paramValue := strings.Join(splitParams[1:], "=")
+
+		flagParams[paramName] = paramValue
+	}
+	return flagParams
+}
+
 // CorkProjectMetadata - used to store metadata about the current project
 type CorkProjectMetadata struct {
 	ID string `json:"id"`
@@ -48,12 +77,52 @@ func (cpm *CorkProjectMetadata) CacheVolumeName() string {
 	return fmt.Sprintf("cork-cache-%s", cpm.ID)
 }
 
+// VolumePrefix - The prefix that scopes the type's named volumes to this project
+func (cpm *CorkProjectMetadata) VolumePrefix() string {
+	return fmt.Sprintf("cork-%s-", cpm.ID)
+}
+
+var signalGracePeriodFlag = cli.DurationFlag{
+	Name:   "signal-grace-period",
+	Usage:  "How long a step has to stop after Ctrl-C or SIGTERM is sent to it before cork kills everything",
+	EnvVar: "CORK_SIGNAL_GRACE_PERIOD",
+	Value:  defaultSignalGracePeriod,
+}
+
+// paramFlags - Flags that set how params are provided to a run
+var paramFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "params-file",
+		Usage:  "A YAML, JSON or dotenv file of params. These override cork.yml params and are overridden by --param and CORK_PARAM_<NAME> environment variables",
+		EnvVar: "CORK_PARAMS_FILE",
+	},
+	cli.StringFlag{
+		Name:   "secrets-file",
+		Usage:  "A YAML, JSON or dotenv file of secret params kept outside of the project. These are used when no other source has a value",
+		EnvVar: "CORK_SECRETS_FILE",
+	},
+	cli.BoolFlag{
+		Name:  "explain-params",
+		Usage: "Print where the value of each param came from. Sensitive values are masked",
+	},
+	cli.StringFlag{
+		Name:   "profile",
+		Usage:  "Use the params of a profile defined in cork.yml. These override cork.yml params",
+		EnvVar: "CORK_PROFILE",
+	},
+	cli.BoolFlag{
+		Name:   "non-interactive",
+		Usage:  "Fail instead of prompting for params without a value. This is on when stdin is not a terminal",
+		EnvVar: "CORK_NON_INTERACTIVE",
+	},
+}
+
 func init() {
 	command := cli.Command{
 		Name:        "run",
 		Description: "Determine available commands",
 		Action:      cmdRun,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			cli.BoolFlag{
 				Name:   "force-pull-image",
 				Usage:  "Forces cork to pull the latest version of the cork container",
@@ -74,18 +143,165 @@ func init() {
 				Name:  "param, p",
 				Usage: `Set Paramater "param_name=param_value"`,
 			},
+			cli.StringSliceFlag{
+				Name:  "tag, t",
+				Usage: "Activate a tag for the run. Steps are included or skipped based on their match_tags and skip_tags",
+			},
 			cli.StringFlag{
 				Name:   "override-cork-server",
 				Usage:  `Path to a directory containing a cork-server to use on the cork type server`,
 				EnvVar: "CORK_OVERRIDE_CORK_SERVER",
 			},
-		},
+			cli.StringFlag{
+				Name:  "from",
+				Usage: "Run the stage from a step. Earlier steps are skipped and their outputs come from the stage's latest run",
+			},
+			cli.StringFlag{
+				Name:  "until",
+				Usage: "Run the stage until a step. Later steps are skipped",
+			},
+			cli.StringFlag{
+				Name:  "only",
+				Usage: "Only run a step of the stage. The outputs of other steps come from the stage's latest run",
+			},
+			signalGracePeriodFlag,
+		}, paramFlags...),
 	}
 	registerCommand(command)
 }
 
+// The sources of params that --explain-params shows. Params files are named by
+// their path and profiles by their name
+const (
+	paramSourceFlag     = "--param"
+	paramSourceEnv      = "environment"
+	paramSourceCorkYaml = "cork.yml"
+	paramSourceSecrets  = "secret store"
+	paramSourceDefault  = "default"
+	paramSourcePrompt   = "prompt"
+)
+
+// newParamProvider - Chains the sources of params from the highest precedence to
+// the lowest. Params without a value from any source are prompted for. The
+// profile is one of corkDef's profiles, or empty for none
+func newParamProvider(c *cli.Context, corkDef *CorkDefinition, profileName string) (*params.ChainParamProvider, error) {
+	providers := []params.NamedParamProvider{
+		{
+			Name:     paramSourceFlag,
+			Provider: &params.MapParamProvider{Params: parseParamFlags(c.StringSlice("param"))},
+		},
+		{
+			Name:     paramSourceEnv,
+			Provider: params.NewEnvProvider(),
+		},
+	}
+
+	if c.String("params-file") != "" {
+		fileParams, err := params.LoadParamsFile(c.String("params-file"))
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, params.NamedParamProvider{
+			Name:     c.String("params-file"),
+			Provider: &params.MapParamProvider{Params: fileParams},
+		})
+	}
+
+	if profileName != "" {
+		profileParams, err := corkDef.ProfileParams(profileName)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, params.NamedParamProvider{
+			Name:     fmt.Sprintf("profile %s", profileName),
+			Provider: &params.MapParamProvider{Params: profileParams},
+		})
+	}
+
+	providers = append(providers, params.NamedParamProvider{
+		Name:     paramSourceCorkYaml,
+		Provider: &params.MapParamProvider{Params: corkDef.Params},
+	})
+
+	if c.String("secrets-file") != "" {
+		secretParams, err := params.LoadParamsFile(c.String("secrets-file"))
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, params.NamedParamProvider{
+			Name:     paramSourceSecrets,
+			Provider: &params.MapParamProvider{Params: secretParams},
+		})
+	}
+
+	interactive := params.NewInteractiveProvider(nil)
+	interactive.NonInteractive = isNonInteractive(c)
+	providers = append(providers,
+		params.NamedParamProvider{
+			Name:     paramSourceDefault,
+			Provider: &params.DefaultParamProvider{},
+		},
+		params.NamedParamProvider{
+			Name:     paramSourcePrompt,
+			Provider: interactive,
+		},
+	)
+
+	provider := params.NewChainProvider(providers...)
+	if c.Bool("explain-params") {
+		provider.Explain = os.Stdout
+	}
+	return provider, nil
+}
+
+// ignoreProfile - Commands that don't read cork.yml have no profiles to use
+func ignoreProfile(c *cli.Context) {
+	if c.String("profile") != "" {
+		color.Yellow(`Warning: ignoring profile "%s". Profiles are defined in cork.yml, which "cork %s" does not use`, c.String("profile"), c.Command.Name)
+	}
+}
+
+// warnUndefinedProfileParams - Warns about params of the profile that the type
+// does not define. These are usually typos or params of another type
+func warnUndefinedProfileParams(corkClient *client.Client, corkDef *CorkDefinition, profileName string) error {
+	if profileName == "" {
+		return nil
+	}
+	profileParams, err := corkDef.ProfileParams(profileName)
+	if err != nil {
+		return err
+	}
+
+	paramDefinitions, err := corkClient.ListParams()
+	if grpc.Code(err) == codes.Unimplemented {
+		log.Debugf("The cork server of %s cannot list its params. Not checking profile %s", corkDef.Type, profileName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var undefined []string
+	for paramName := range profileParams {
+		if _, ok := paramDefinitions[paramName]; !ok {
+			undefined = append(undefined, paramName)
+		}
+	}
+	sort.Strings(undefined)
+	for _, paramName := range undefined {
+		color.Yellow(`Warning: param "%s" of profile "%s" is not defined by %s`, paramName, profileName, corkDef.Type)
+	}
+	return nil
+}
+
+// isNonInteractive - Checks if params must not be prompted for. Without a
+// terminal to prompt on a run would wait forever
+func isNonInteractive(c *cli.Context) bool {
+	return c.Bool("non-interactive") || !client.StdinIsTerminal()
+}
+
 func loadCorkYaml() (*CorkDefinition, error) {
-	file, err := os.Open("cork.yml")
+	file, err := os.Open(corkYamlPath)
 	if err != nil {
 		return nil, fmt.Errorf("Cannot properly read the cork.yml file. Does it exist?")
 	}
@@ -114,12 +330,21 @@ func loadCorkYaml() (*CorkDefinition, error) {
 	return &corkDef, nil
 }
 
+// The default time a step has to stop after being sent a signal
+const defaultSignalGracePeriod = 10 * time.Second
+
 type Control struct {
 	Kill             chan os.Signal
 	Done             chan bool
 	ChildKillSignals []chan bool
 	ChildrenCount    int
 	Terminating      bool
+
+	// How long after forwarding a signal to wait before terminating
+	GracePeriod time.Duration
+
+	lock    sync.Mutex
+	forward chan os.Signal
 }
 
 // Add a child
@@ -159,22 +384,66 @@ func NewControl() *Control {
 		Kill:          killChan,
 		Done:          doneChan,
 		ChildrenCount: 0,
+		GracePeriod:   defaultSignalGracePeriod,
 	}
 }
 
+// ForwardSignals - Sends signals to the returned channel so the running step can
+// stop on its own. Cork only terminates if the run hasn't ended after the grace
+// period or when another signal is received
+func (c *Control) ForwardSignals() <-chan os.Signal {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.forward = make(chan os.Signal, 1)
+	return c.forward
+}
+
+// StopForwardingSignals - Terminates on the next signal
+func (c *Control) StopForwardingSignals() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.forward = nil
+}
+
+func (c *Control) forwardSignal(sig os.Signal) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.forward == nil {
+		return false
+	}
+	select {
+	case c.forward <- sig:
+	default:
+	}
+	return true
+}
+
 func (c *Control) HandleTerminate() {
 	signal.Notify(c.Kill, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		<-c.Kill
+		sig := <-c.Kill
+		if c.forwardSignal(sig) {
+			color.Yellow("\nSent %v to the running step. Cork will stop in %s or on another signal", sig, c.GracePeriod)
+			select {
+			case <-c.Kill:
+			case <-time.After(c.GracePeriod):
+			}
+		}
 		c.Terminating = true
 		c.NotifyAll()
 		c.WaitForAll()
-		os.Exit(1)
+
+		// Deferred calls don't run on exit
+		client.RestoreTerminal()
+		os.Exit(exitStatusTerminated)
 	}()
 }
 
+// The directory of a project's cork metadata and run records
+const corkMetadataDir = ".cork"
+
 func loadCorkProjectMetadata() (*CorkProjectMetadata, error) {
-	metadataDir := ".cork"
+	metadataDir := corkMetadataDir
 	metadataJSONPath := path.Join(metadataDir, "metadata.json")
 	if _, err := os.Stat(metadataDir); os.IsNotExist(err) {
 		os.Mkdir(metadataDir, 0700)
@@ -210,8 +479,36 @@ func loadCorkProjectMetadata() (*CorkProjectMetadata, error) {
 	return &metadata, nil
 }
 
-func executeCorkRun(c *cli.Context, corkDef *CorkDefinition, stageName string) error {
+// The exit statuses of cork run. A failed step exits with the step's exit code
+const (
+	exitStatusStepFailed     = 1
+	exitStatusDefinition     = 2
+	exitStatusInfrastructure = 3
+	exitStatusTerminated     = 130
+)
+
+// exitStatus - The exit status for the error that stopped a run. Steps that
+// failed without an exit code, like those that timed out, exit with 1
+func exitStatus(err error) int {
+	stageErr, ok := err.(client.StageError)
+	if !ok {
+		return exitStatusInfrastructure
+	}
+	switch stageErr.Kind {
+	case pb.ErrorKindStep:
+		if stageErr.ExitCode > 0 {
+			return stageErr.ExitCode
+		}
+		return exitStatusStepFailed
+	case pb.ErrorKindDefinition:
+		return exitStatusDefinition
+	}
+	return exitStatusInfrastructure
+}
+
+func executeCorkRun(c *cli.Context, corkDef *CorkDefinition, stageName string, profileName string) error {
 	control := NewControl()
+	control.GracePeriod = c.Duration("signal-grace-period")
 	control.HandleTerminate()
 
 	log.Debugf("Loading cork metadata for project %s", corkDef.Name)
@@ -221,24 +518,14 @@ func executeCorkRun(c *cli.Context, corkDef *CorkDefinition, stageName string) e
 	}
 
 	log.Debug("Connecting to docker")
-	client, err := docker.NewClientFromEnv()
+	dockerClient, err := docker.NewClientFromEnv()
 	if err != nil {
 		return err
 	}
 
-	params := c.StringSlice("param")
-
-	if corkDef.Params == nil {
-		corkDef.Params = make(map[string]string)
-	}
-
-	for _, rawParam := range params {
-		splitParams := strings.Split(rawParam, "=")
-
-		paramName := splitParams[0]
-		paramValue := strings.Join(splitParams[1:], "=")
-
-		corkDef.Params[paramName] = paramValue
+	paramProvider, err := newParamProvider(c, corkDef, profileName)
+	if err != nil {
+		return err
 	}
 
 	cliOutput := c.String("output")
@@ -251,9 +538,22 @@ func executeCorkRun(c *cli.Context, corkDef *CorkDefinition, stageName string) e
 		return err
 	}
 
+	recorder, err := newRunStore().Start(&runlog.Run{
+		ID:    uuid.NewV4().String(),
+		Stage: stageName,
+		Tags:  c.StringSlice("tag"),
+	})
+	if err != nil {
+		return err
+	}
+	control.OnTerminate(func() {
+		recorder.Finish(runlog.StatusTerminated, nil)
+	})
+
 	options := CorkTypeContainerOptions{
 		ProjectName:               corkDef.Name,
 		CacheVolumeName:           metadata.CacheVolumeName(),
+		VolumePrefix:              metadata.VolumePrefix(),
 		ImageName:                 corkDef.Type,
 		Debug:                     c.GlobalBool("debug"),
 		ForcePullImage:            c.Bool("force-pull-image"),
@@ -261,11 +561,20 @@ func executeCorkRun(c *cli.Context, corkDef *CorkDefinition, stageName string) e
 		Definition:                corkDef,
 		OutputDestinationPath:     outputDestinationPath,
 		OverrideCorkServerDirPath: c.String("override-cork-server"),
+		Tags:                      c.StringSlice("tag"),
+		RunID:                     recorder.ID(),
+		From:                      c.String("from"),
+		Until:                     c.String("until"),
+		Only:                      c.String("only"),
+		Recorder:                  recorder,
+		ParamProvider:             paramProvider,
 	}
 
 	log.Debug("Initializing runner")
-	runner, err := New(client, control, options)
+	runner, err := New(dockerClient, control, options)
 	if err != nil {
+		// The run ends before it starts. Its record must not stay running
+		recorder.Finish(runlog.StatusFailed, err)
 		return err
 	}
 
@@ -282,12 +591,33 @@ func executeCorkRun(c *cli.Context, corkDef *CorkDefinition, stageName string) e
 	black.Printf("%s\n", corkDef.Type)
 	blue.Printf("Executing Stage: ")
 	black.Printf("%s\n", stageName)
+	if profileName != "" {
+		blue.Printf("Profile: ")
+		black.Printf("%s\n", profileName)
+	}
+	blue.Printf("Run: ")
+	black.Printf("%s\n", options.RunID)
 	blue.Printf("-------------------\n")
 
-	err = runner.Start(stageName)
+	err = runner.Run(func(corkClient *client.Client) error {
+		err := warnUndefinedProfileParams(corkClient, corkDef, profileName)
+		if err != nil {
+			return err
+		}
+		return runner.executeStage(corkClient, stageName)
+	})
 	if control.Terminating {
 		color.Red("\nCork run terminated")
 	}
+
+	status := runlog.StatusSucceeded
+	if err != nil {
+		status = runlog.StatusFailed
+	}
+	finishErr := recorder.Finish(status, err)
+	if finishErr != nil {
+		log.Debugf("Could not record the end of run %s: %v", recorder.ID(), finishErr)
+	}
 	if err != nil {
 		if !(strings.Contains(err.Error(), "without exit status") && control.Terminating) {
 			color.Red("\nCork failed")
@@ -312,9 +642,12 @@ func executeCorkRun(c *cli.Context, corkDef *CorkDefinition, stageName string) e
 				color.Red("with the appropriate key. Try setting `cork --debug` for more information.")
 			}
 			log.Errorf("%v", err)
-			os.Exit(1)
+			os.Exit(exitStatus(err))
 		}
 	}
+	if control.Terminating {
+		os.Exit(exitStatusTerminated)
+	}
 	color.Green("\nCork is done!")
 	color.Green("Find your outputs: %s", outputDestinationPath)
 	return nil
@@ -332,7 +665,7 @@ func cmdRun(c *cli.Context) error {
 		stageName = "default"
 	}
 
-	err = executeCorkRun(c, corkDef, stageName)
+	err = executeCorkRun(c, corkDef, stageName, c.String("profile"))
 	if err != nil {
 		return err
 	}
@@ -12,6 +12,8 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/virtru/cork/client"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/definition"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 
@@ -30,6 +32,13 @@ type VolumeMap map[string]string
 
 var serverCommandTemplate = "/cork-server/cork-server -e %s serve"
 
+// Paths inside of the cork type container
+const (
+	containerWorkDir        = "/work"
+	containerCacheDir       = "/cork-cache"
+	containerDefinitionPath = "/cork/definition.yml"
+)
+
 // CorkTypeContainer - Runs a cork job in a container
 type CorkTypeContainer struct {
 	Name                      string
@@ -42,6 +51,7 @@ type CorkTypeContainer struct {
 	SSHPort                   int
 	CorkPort                  int
 	CacheVolumeName           string
+	VolumePrefix              string
 	Env                       []string
 	ProjectName               string
 	ForcePullImage            bool
@@ -51,18 +61,45 @@ type CorkTypeContainer struct {
 	Definition                *CorkDefinition
 	OutputDestinationPath     string
 	OverrideCorkServerDirPath string
+	Tags                      []string
+	RunID                     string
+
+	// Select the steps of the stage to run
+	From  string
+	Until string
+	Only  string
+
+	// Records the run of the stage
+	Recorder client.RunRecorder
+
+	// Provides the params of the stage
+	ParamProvider client.ParamProvider
 }
 
 type CorkTypeContainerOptions struct {
 	Debug                     bool
 	ImageName                 string
 	CacheVolumeName           string
+	VolumePrefix              string
 	ProjectName               string
 	ForcePullImage            bool
 	SSHKeyPath                string
 	Definition                *CorkDefinition
 	OutputDestinationPath     string
 	OverrideCorkServerDirPath string
+	Tags                      []string
+	RunID                     string
+
+	// Select the steps of the stage to run
+	From  string
+	Until string
+	Only  string
+
+	// Records the run of the stage
+	Recorder client.RunRecorder
+
+	// Provides the params of the stage
+	ParamProvider client.ParamProvider
 }
 
 // Creates a new cork runner
@@ -90,6 +127,7 @@ func New(dockerClient *docker.Client, control *Control, options CorkTypeContaine
 		Name:                      fmt.Sprintf("cork-%s", uuid.NewV4()),
 		DockerHostPath:            dockerHostPath,
 		CacheVolumeName:           options.CacheVolumeName,
+		VolumePrefix:              options.VolumePrefix,
 		Control:                   control,
 		ProjectName:               options.ProjectName,
 		ForcePullImage:            options.ForcePullImage,
@@ -98,11 +136,29 @@ func New(dockerClient *docker.Client, control *Control, options CorkTypeContaine
 		Definition:                options.Definition,
 		OutputDestinationPath:     options.OutputDestinationPath,
 		OverrideCorkServerDirPath: options.OverrideCorkServerDirPath,
+		Tags:                      options.Tags,
+		RunID:                     options.RunID,
+		From:                      options.From,
+		Until:                     options.Until,
+		Only:                      options.Only,
+		Recorder:                  options.Recorder,
+		ParamProvider:             options.ParamProvider,
 	}
 	return &runner, nil
 }
 
+// ClientAction - Uses the cork server once it is running
+type ClientAction func(corkClient *client.Client) error
+
+// Start - Runs a stage in the cork type container
 func (c *CorkTypeContainer) Start(stageName string) error {
+	return c.Run(func(corkClient *client.Client) error {
+		return c.executeStage(corkClient, stageName)
+	})
+}
+
+// Run - Starts the cork type container and its server then runs the action against it
+func (c *CorkTypeContainer) Run(action ClientAction) error {
 	sshPort := freeport.GetPort()
 	corkPort := freeport.GetPort()
 	c.SSHPort = sshPort
@@ -115,6 +171,11 @@ func (c *CorkTypeContainer) Start(stageName string) error {
 	}
 	c.Commander = commander
 
+	err = c.addDefinitionVolumes()
+	if err != nil {
+		return err
+	}
+
 	err = commander.Start()
 	if err != nil {
 		return err
@@ -125,7 +186,7 @@ func (c *CorkTypeContainer) Start(stageName string) error {
 		commander.Kill()
 	})
 
-	err = c.startSSHCommand(stageName)
+	err = c.startSSHCommand(action)
 	if err != nil {
 		log.Debugf("Error occured running SSH Command")
 		return err
@@ -133,6 +194,53 @@ func (c *CorkTypeContainer) Start(stageName string) error {
 	return nil
 }
 
+// addDefinitionVolumes - Mounts the named volumes declared in the type's
+// definition.yml. Types without a definition.yml have no volumes
+func (c *CorkTypeContainer) addDefinitionVolumes() error {
+	log.Debugf("Reading %s from %s", containerDefinitionPath, c.Image)
+	defBytes, err := c.Commander.ReadImageFile(containerDefinitionPath)
+	if dockerutils.IsImageFileNotFound(err) {
+		log.Debugf("%s has no %s. Not mounting any volumes", c.Image, containerDefinitionPath)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	serverDef, err := definition.LoadVolumesFromBytes(defBytes)
+	if err != nil {
+		return client.StageError{
+			Message:  err.Error(),
+			Kind:     pb.ErrorKindDefinition,
+			ExitCode: -1,
+		}
+	}
+
+	pwd, err := c.Pwd()
+	if err != nil {
+		return err
+	}
+
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir:      containerWorkDir,
+		HostWorkDir:  pwd,
+		CacheDir:     containerCacheDir,
+		VolumePrefix: c.VolumePrefix,
+	})
+
+	mounts, err := serverDef.VolumeMounts(renderer)
+	if err != nil {
+		return err
+	}
+	volumeNames := serverDef.VolumeNames(renderer)
+	log.Debugf("Mounting volumes %v with %v", volumeNames, mounts)
+
+	options := &c.Commander.Options
+	options.EnsureNamedVolumes = append(options.EnsureNamedVolumes, volumeNames...)
+	options.Binds = append(options.Binds, mounts...)
+	return nil
+}
+
 func (c *CorkTypeContainer) connectClient() (*client.Client, error) {
 	log.Debugf("Connecting to cork server on port %d", c.CorkPort)
 	//time.Sleep(200 * time.Second)
@@ -160,10 +268,13 @@ func (c *CorkTypeContainer) connectClient() (*client.Client, error) {
 }
 
 func (c *CorkTypeContainer) getParamsProvider() client.ParamProvider {
+	if c.ParamProvider != nil {
+		return c.ParamProvider
+	}
 	return params.NewInteractiveProvider(c.Definition.Params)
 }
 
-func (c *CorkTypeContainer) runClient(stageName string, clientErrChan chan error) {
+func (c *CorkTypeContainer) runClient(action ClientAction, clientErrChan chan error) {
 	go func() {
 		corkClient, err := c.connectClient()
 		if err != nil {
@@ -173,28 +284,13 @@ func (c *CorkTypeContainer) runClient(stageName string, clientErrChan chan error
 		}
 		defer corkClient.Close()
 
-		log.Debugf("Running stage %s", stageName)
-		exports, err := corkClient.StageExecute(stageName, c.getParamsProvider())
-		if err != nil {
-			log.Debugf("Error occured running StageExecute")
-			clientErrChan <- err
-			return
-		}
-
-		log.Debugf("Writing exports to %s", c.OutputDestinationPath)
-		exportsJSONBytes, err := json.Marshal(exports)
-		if err != nil {
-			clientErrChan <- err
-			return
-		}
-
-		err = ioutil.WriteFile(c.OutputDestinationPath, exportsJSONBytes, 0600)
+		err = action(corkClient)
 		if err != nil {
 			clientErrChan <- err
 			return
 		}
 
-		log.Debugf("Stage executed successfully. Killing cork server")
+		log.Debugf("Client is done. Killing cork server")
 		err = corkClient.Kill()
 		if err != nil {
 			clientErrChan <- err
@@ -204,6 +300,40 @@ func (c *CorkTypeContainer) runClient(stageName string, clientErrChan chan error
 	}()
 }
 
+// executeStage - Executes a stage and writes its exports to the output destination
+func (c *CorkTypeContainer) executeStage(corkClient *client.Client, stageName string) error {
+	log.Debugf("Running stage %s with tags %v", stageName, c.Tags)
+	corkClient.Signals = c.Control.ForwardSignals()
+	corkClient.Recorder = c.Recorder
+	defer c.Control.StopForwardingSignals()
+
+	exports, err := corkClient.StageExecute(&pb.StageExecuteRequestEvent{
+		Stage: stageName,
+		Tags:  c.Tags,
+		RunId: c.RunID,
+		From:  c.From,
+		Until: c.Until,
+		Only:  c.Only,
+	}, c.getParamsProvider())
+	if err != nil {
+		log.Debugf("Error occured running StageExecute")
+		return err
+	}
+
+	log.Debugf("Writing exports to %s", c.OutputDestinationPath)
+	exportsJSONBytes, err := json.Marshal(exports)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(c.OutputDestinationPath, exportsJSONBytes, 0600)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Stage executed successfully")
+	return nil
+}
+
 func (c *CorkTypeContainer) setupDockerCreds() error {
 	log.Debugf("Setting docker credentials for container")
 	configStr, err := dockerutils.ExportAuthConfigsFromDockerCfg()
@@ -238,7 +368,7 @@ func (c *CorkTypeContainer) setupDockerCreds() error {
 	return nil
 }
 
-func (c *CorkTypeContainer) startSSHCommand(stageName string) error {
+func (c *CorkTypeContainer) startSSHCommand(action ClientAction) error {
 	failed := make(chan bool)
 
 	err := c.setupDockerCreds()
@@ -270,7 +400,7 @@ func (c *CorkTypeContainer) startSSHCommand(stageName string) error {
 	defer command.CleanUp()
 	clientErrChan := make(chan error)
 
-	c.runClient(stageName, clientErrChan)
+	c.runClient(action, clientErrChan)
 
 	for {
 		select {
@@ -312,13 +442,14 @@ func (c *CorkTypeContainer) createCommander() (*dockerutils.DockerCommander, err
 		"CORK_HOST_WORK_DIR",
 		"CORK_PROJECT_NAME",
 		"CORK_HOST_HOME_DIR",
+		"CORK_VOLUME_PREFIX",
 	}
 
 	volumeBinds := []string{
 		fmt.Sprintf("%s:/var/run/docker.sock", c.DockerHostPath),
-		fmt.Sprintf("%s:/work", pwd),
+		fmt.Sprintf("%s:%s", pwd, containerWorkDir),
 		fmt.Sprintf("%s:/host_home", homeDir),
-		fmt.Sprintf("%s:/cork-cache", c.CacheVolumeName),
+		fmt.Sprintf("%s:%s", c.CacheVolumeName, containerCacheDir),
 	}
 	if c.OverrideCorkServerDirPath != "" {
 		volumeBinds = append(volumeBinds, fmt.Sprintf("%s:/cork-server", c.OverrideCorkServerDirPath))
@@ -330,11 +461,12 @@ func (c *CorkTypeContainer) createCommander() (*dockerutils.DockerCommander, err
 		Env: []string{
 			"DOCKER_HOST=unix:///var/run/docker.sock",
 			"CORK_PORT=11900",
-			"CORK_WORK_DIR=/work",
-			"CORK_CACHE_DIR=/cork-cache",
+			fmt.Sprintf("CORK_WORK_DIR=%s", containerWorkDir),
+			fmt.Sprintf("CORK_CACHE_DIR=%s", containerCacheDir),
 			"CORK_HOST_HOME_DIR=/host_home",
 			fmt.Sprintf("CORK_HOST_WORK_DIR=%s", pwd),
 			fmt.Sprintf("CORK_PROJECT_NAME=%s", c.ProjectName),
+			fmt.Sprintf("CORK_VOLUME_PREFIX=%s", c.VolumePrefix),
 		},
 		Expose: []int{
 			22,
@@ -1,8 +1,22 @@
 package capture
 
-// OutputCaptor - Takes output from a stream and streams it to a callback
+import (
+	"sync"
+	"time"
+)
+
+// HoldBackTimeout - How long output that could be the start of a secret is held
+// back when no more output arrives. A prompt waiting for input is still shown
+const HoldBackTimeout = 100 * time.Millisecond
+
+// OutputCaptor - Takes output from a stream and streams it to a callback. If the
+// captor has a redactor, secrets are scrubbed from the output first
 type OutputCaptor struct {
 	OutputHandler func(p []byte) error
+	Redactor      *Redactor
+
+	lock       sync.Mutex
+	flushTimer *time.Timer
 }
 
 // New - Creates a new captor
@@ -12,8 +26,59 @@ func New(outputHandler func(p []byte) error) *OutputCaptor {
 	}
 }
 
+// NewRedacting - Creates a new captor that scrubs secrets from the output
+func NewRedacting(secrets []string, outputHandler func(p []byte) error) *OutputCaptor {
+	return &OutputCaptor{
+		OutputHandler: outputHandler,
+		Redactor:      NewRedactor(secrets),
+	}
+}
+
 func (o *OutputCaptor) Write(p []byte) (int, error) {
 	n := len(p)
-	err := o.OutputHandler(p)
+	if o.Redactor == nil {
+		err := o.OutputHandler(p)
+		return n, err
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	err := o.handle(o.Redactor.Redact(p))
+	if o.Redactor.Pending() {
+		o.flushAfterTimeout()
+	}
 	return n, err
 }
+
+// flushAfterTimeout - Flushes the held back output unless more output arrives first
+func (o *OutputCaptor) flushAfterTimeout() {
+	if o.flushTimer == nil {
+		o.flushTimer = time.AfterFunc(HoldBackTimeout, func() {
+			// The output's stream reports its own errors on the next write
+			o.Flush()
+		})
+		return
+	}
+	o.flushTimer.Reset(HoldBackTimeout)
+}
+
+// Flush - Sends any output held back because it could be the start of a secret
+func (o *OutputCaptor) Flush() error {
+	if o.Redactor == nil {
+		return nil
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.flushTimer != nil {
+		o.flushTimer.Stop()
+	}
+	return o.handle(o.Redactor.Flush())
+}
+
+func (o *OutputCaptor) handle(p []byte) error {
+	if len(p) == 0 {
+		return nil
+	}
+	return o.OutputHandler(p)
+}
@@ -0,0 +1,104 @@
+package capture
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Mask - Replaces secrets in redacted output
+const Mask = "******"
+
+// MinSecretLength - Shorter secrets aren't scrubbed. Masking them would hide
+// most of the output and hold back nearly every write
+const MinSecretLength = 4
+
+// Redactor - Scrubs secrets from a stream of output. Output that could be the
+// start of a secret is held back until the next write shows if it is one, so a
+// secret split across writes is still scrubbed
+type Redactor struct {
+	secrets [][]byte
+	pending []byte
+}
+
+// NewRedactor - Creates a new redactor for the secrets. Secrets shorter than
+// MinSecretLength are ignored
+func NewRedactor(secrets []string) *Redactor {
+	redactor := &Redactor{}
+	for _, secret := range secrets {
+		if len(secret) >= MinSecretLength {
+			redactor.secrets = append(redactor.secrets, []byte(secret))
+		}
+	}
+
+	// Prefer the longest match when secrets overlap
+	sort.Slice(redactor.secrets, func(i, j int) bool {
+		return len(redactor.secrets[i]) > len(redactor.secrets[j])
+	})
+	return redactor
+}
+
+// Redact - Scrubs the secrets from the output that is safe to send
+func (r *Redactor) Redact(p []byte) []byte {
+	r.pending = append(r.pending, p...)
+	redacted, rest := r.scan(r.pending, false)
+	r.pending = append([]byte(nil), rest...)
+	return redacted
+}
+
+// Pending - Checks if output is being held back
+func (r *Redactor) Pending() bool {
+	return len(r.pending) > 0
+}
+
+// Flush - Scrubs and returns any output that is being held back
+func (r *Redactor) Flush() []byte {
+	redacted, _ := r.scan(r.pending, true)
+	r.pending = nil
+	return redacted
+}
+
+// scan - Replaces the secrets in data. Unless this is the final scan, scanning
+// stops at the first byte that begins an incomplete secret and the rest is returned
+func (r *Redactor) scan(data []byte, final bool) ([]byte, []byte) {
+	var redacted bytes.Buffer
+	i := 0
+	for i < len(data) {
+		secret := r.secretAt(data[i:])
+		if secret != nil {
+			redacted.WriteString(Mask)
+			i += len(secret)
+			continue
+		}
+		if !final && r.startsSecret(data[i:]) {
+			return redacted.Bytes(), data[i:]
+		}
+		redacted.WriteByte(data[i])
+		i++
+	}
+	return redacted.Bytes(), nil
+}
+
+func (r *Redactor) secretAt(data []byte) []byte {
+	for _, secret := range r.secrets {
+		if bytes.HasPrefix(data, secret) {
+			return secret
+		}
+	}
+	return nil
+}
+
+func (r *Redactor) startsSecret(data []byte) bool {
+	for _, secret := range r.secrets {
+		if len(data) < len(secret) && bytes.HasPrefix(secret, data) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString - Scrubs the secrets from a complete value
+func RedactString(secrets []string, value string) string {
+	redactor := NewRedactor(secrets)
+	redacted := redactor.Redact([]byte(value))
+	return string(append(redacted, redactor.Flush()...))
+}
@@ -0,0 +1,71 @@
+package capture_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/virtru/cork/server/capture"
+)
+
+func TestRedactingCaptor(t *testing.T) {
+	var output []byte
+	captor := capture.NewRedacting([]string{"hunter2", "s3cr3t"}, func(p []byte) error {
+		output = append(output, p...)
+		return nil
+	})
+
+	// The secrets are split across writes like pty reads can split them
+	writes := []string{"password=hun", "ter2 token=s", "3cr3", "t done. hun", "gry\n"}
+	for _, write := range writes {
+		n, err := captor.Write([]byte(write))
+		assert.NoError(t, err)
+		assert.Equal(t, len(write), n)
+	}
+	assert.NoError(t, captor.Flush())
+
+	assert.Equal(t, "password=****** token=****** done. hungry\n", string(output))
+}
+
+func TestRedactorHoldsBackPartialSecrets(t *testing.T) {
+	redactor := capture.NewRedactor([]string{"hunter2"})
+
+	assert.Equal(t, "user ", string(redactor.Redact([]byte("user hunt"))))
+	assert.Equal(t, "hunt", string(redactor.Flush()))
+}
+
+func TestRedactingCaptorShowsHeldBackOutputWhenIdle(t *testing.T) {
+	var lock sync.Mutex
+	var output []byte
+	captor := capture.NewRedacting([]string{"hunter2"}, func(p []byte) error {
+		lock.Lock()
+		defer lock.Unlock()
+		output = append(output, p...)
+		return nil
+	})
+	shown := func() string {
+		lock.Lock()
+		defer lock.Unlock()
+		return string(output)
+	}
+
+	// A prompt that ends like a secret starts is shown while it waits for input
+	_, err := captor.Write([]byte("Continue? [h"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Continue? [", shown())
+	deadline := time.Now().Add(time.Second)
+	for shown() != "Continue? [h" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, "Continue? [h", shown())
+}
+
+func TestRedactString(t *testing.T) {
+	assert.Equal(t, "a ****** b", capture.RedactString([]string{"hunter2", ""}, "a hunter2 b"))
+	assert.Equal(t, "nothing", capture.RedactString(nil, "nothing"))
+}
+
+func TestRedactorIgnoresShortSecrets(t *testing.T) {
+	assert.Equal(t, "a b c ****** ab", capture.RedactString([]string{"a", "ab", "abc", "abcd"}, "a b c abcd ab"))
+}
@@ -0,0 +1,76 @@
+package definition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefinitionError - A problem with a definition. Problems with a step include
+// where the step is defined when it is known
+type DefinitionError struct {
+	Message  string
+	Position *SourcePosition
+}
+
+func (de DefinitionError) Error() string {
+	if de.Position == nil {
+		return de.Message
+	}
+	return fmt.Sprintf("%s (line %d, column %d)", de.Message, de.Position.Line, de.Position.Column)
+}
+
+// stepError - Creates an error for a problem with a step
+func stepError(step *Step, format string, args ...interface{}) error {
+	return DefinitionError{
+		Message:  fmt.Sprintf(format, args...),
+		Position: step.Position(),
+	}
+}
+
+// DefinitionErrors - Every problem found while validating a definition
+type DefinitionErrors []error
+
+func (des DefinitionErrors) Error() string {
+	var messages []string
+	for _, err := range des {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// errorList - Collects errors, skipping any that were already reported
+type errorList struct {
+	errors   DefinitionErrors
+	messages map[string]bool
+}
+
+func (el *errorList) Add(err error) {
+	if err == nil {
+		return
+	}
+	if errs, ok := err.(DefinitionErrors); ok {
+		for _, err := range errs {
+			el.Add(err)
+		}
+		return
+	}
+	if el.messages == nil {
+		el.messages = map[string]bool{}
+	}
+	if el.messages[err.Error()] {
+		return
+	}
+	el.messages[err.Error()] = true
+	el.errors = append(el.errors, err)
+}
+
+// Err - Gets the collected errors. A single error is returned as is
+func (el *errorList) Err() error {
+	switch len(el.errors) {
+	case 0:
+		return nil
+	case 1:
+		return el.errors[0]
+	}
+	return el.errors
+}
@@ -0,0 +1,139 @@
+package definition
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatrixCombination - One combination of the values in a stage step's matrix. The
+// steps of the stage are copied for each combination and named with its suffix.
+// A combination nested in another matrix has that combination as its parent
+type MatrixCombination struct {
+	// The stage step that has the matrix
+	StepName string
+	Keys     []string
+	Values   map[string]string
+
+	parent *MatrixCombination
+
+	// The names the copied steps had before they were namespaced
+	stepNames map[string]bool
+}
+
+// Suffix - Added to the names of the steps of the combination, e.g. "[node=4]".
+// Suffixes of enclosing matrices follow
+func (mc *MatrixCombination) Suffix() string {
+	if mc == nil {
+		return ""
+	}
+	var pairs []string
+	for _, key := range mc.Keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, mc.Values[key]))
+	}
+	return fmt.Sprintf("[%s]%s", strings.Join(pairs, ","), mc.parent.Suffix())
+}
+
+// Name - The name of the combination used in the run summary
+func (mc *MatrixCombination) Name() string {
+	return mc.StepName + mc.Suffix()
+}
+
+// Parent - The combination of the enclosing matrix, if any
+func (mc *MatrixCombination) Parent() *MatrixCombination {
+	return mc.parent
+}
+
+// Value - Gets a matrix value. Values of the combination hide those of enclosing matrices
+func (mc *MatrixCombination) Value(key string) (string, bool) {
+	for combination := mc; combination != nil; combination = combination.parent {
+		value, ok := combination.Values[key]
+		if ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// namespace - Namespaces the name of a step in the combination. Names of steps
+// outside of it are returned as is
+func (mc *MatrixCombination) namespace(name string) string {
+	for combination := mc; combination != nil; combination = combination.parent {
+		if combination.stepNames[name] {
+			return name + combination.Suffix()
+		}
+	}
+	return name
+}
+
+// matrixCombinations - Lists every combination of a stage step's matrix. Keys are
+// sorted and the values of the last key change first
+func matrixCombinations(step *Step) ([]*MatrixCombination, error) {
+	var keys []string
+	for key, values := range step.Matrix {
+		if len(values) == 0 {
+			return nil, stepError(step, `Invalid Definition: matrix key "%s" of step "%s" needs at least one value`, key, step.ReferenceName())
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	combinations := []map[string]string{{}}
+	for _, key := range keys {
+		var expanded []map[string]string
+		for _, combination := range combinations {
+			for _, value := range step.Matrix[key] {
+				values := map[string]string{key: value}
+				for existingKey, existingValue := range combination {
+					values[existingKey] = existingValue
+				}
+				expanded = append(expanded, values)
+			}
+		}
+		combinations = expanded
+	}
+
+	var matrixCombinations []*MatrixCombination
+	for _, values := range combinations {
+		matrixCombinations = append(matrixCombinations, &MatrixCombination{
+			StepName:  step.ReferenceName(),
+			Keys:      keys,
+			Values:    values,
+			stepNames: map[string]bool{},
+		})
+	}
+	return matrixCombinations, nil
+}
+
+// copyForCombination - Copies resolved steps for a matrix combination and
+// namespaces their names. Steps from a nested matrix keep their combination which
+// becomes part of this one
+func copyForCombination(steps []*Step, combination *MatrixCombination) []*Step {
+	var copies []*Step
+	for _, step := range steps {
+		stepCopy := *step
+		if step.Name != "" {
+			combination.stepNames[step.Name] = true
+			stepCopy.Name = step.Name + combination.Suffix()
+		}
+
+		if step.Combination == nil {
+			stepCopy.Combination = combination
+		} else {
+			outermost := step.Combination
+			for outermost.parent != nil {
+				outermost = outermost.parent
+			}
+			if outermost != combination {
+				outermost.parent = combination
+			}
+		}
+
+		stepCopy.Branches = nil
+		for _, branch := range step.Branches {
+			stepCopy.Branches = append(stepCopy.Branches, copyForCombination(branch, combination))
+		}
+		copies = append(copies, &stepCopy)
+	}
+	return copies
+}
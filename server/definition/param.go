@@ -0,0 +1,192 @@
+package definition
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamTypes - Defines param types. An empty type is a string
+var ParamTypes = map[string]bool{
+	"":       true,
+	"string": true,
+	"bool":   true,
+	"int":    true,
+	"enum":   true,
+	"list":   true,
+}
+
+// Param - Defines a param the user provides to a stage
+type Param struct {
+	Type        string   `yaml:"type"`
+	Default     *string  `yaml:"default,omitempty"`
+	Description string   `yaml:"description"`
+	IsSensitive bool     `yaml:"is_sensitive"`
+	Choices     []string `yaml:"choices,omitempty"`
+	Pattern     string   `yaml:"pattern,omitempty"`
+}
+
+func (p Param) HasDefault() bool {
+	return p.Default != nil
+}
+
+// ListValues - Splits the value of a list param into its items
+func ListValues(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validate - Checks the param's definition and its default value
+func (p Param) validate(name string) error {
+	if !ParamTypes[p.Type] {
+		return fmt.Errorf(`Invalid Definition: param "%s" has unknown type "%s"`, name, p.Type)
+	}
+	if p.Type == "enum" && len(p.Choices) == 0 {
+		return fmt.Errorf(`Invalid Definition: enum param "%s" must define choices`, name)
+	}
+	if p.Type != "enum" && len(p.Choices) != 0 {
+		return fmt.Errorf(`Invalid Definition: param "%s" defines choices but is not an enum`, name)
+	}
+	if p.Pattern != "" {
+		if p.Type == "bool" || p.Type == "enum" {
+			return fmt.Errorf(`Invalid Definition: %s param "%s" cannot have a pattern`, p.Type, name)
+		}
+		_, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf(`Invalid Definition: param "%s" has an invalid pattern. %v`, name, err)
+		}
+	}
+	if p.HasDefault() {
+		err := p.ValidateValue(*p.Default)
+		if err != nil {
+			return fmt.Errorf(`Invalid Definition: default for param "%s" is invalid. %v`, name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateValue - Checks that a value provided for the param matches its type
+func (p Param) ValidateValue(value string) error {
+	switch p.Type {
+	case "bool":
+		_, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf(`Expected true or false but got "%s"`, value)
+		}
+		return nil
+	case "int":
+		_, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf(`Expected an integer but got "%s"`, value)
+		}
+	case "enum":
+		for _, choice := range p.Choices {
+			if choice == value {
+				return nil
+			}
+		}
+		return fmt.Errorf(`Expected one of %s but got "%s"`, strings.Join(p.Choices, ", "), value)
+	case "list":
+		for _, item := range ListValues(value) {
+			err := p.matchPattern(item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return p.matchPattern(value)
+}
+
+// matchPattern - Checks that the whole value matches the param's pattern
+func (p Param) matchPattern(value string) error {
+	if p.Pattern == "" {
+		return nil
+	}
+	pattern, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", p.Pattern))
+	if err != nil {
+		return err
+	}
+	if !pattern.MatchString(value) {
+		return fmt.Errorf(`Expected a value matching "%s" but got "%s"`, p.Pattern, value)
+	}
+	return nil
+}
+
+func (sd *ServerDefinition) validateParams() error {
+	var names []string
+	for name := range sd.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := &errorList{}
+	for _, name := range names {
+		errs.Add(sd.Params[name].validate(name))
+	}
+	return errs.Err()
+}
+
+// CheckParamValuesProvided - Checks that a value was provided for each required param
+func CheckParamValuesProvided(requiredParams []string, values map[string]string) error {
+	var missing []string
+	for _, name := range requiredParams {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("Missing values for params: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ValidateParamValues - Checks the values a user provided against the param definitions
+func (sd *ServerDefinition) ValidateParamValues(values map[string]string) error {
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		param, ok := sd.Params[name]
+		if !ok {
+			continue
+		}
+		err := param.ValidateValue(values[name])
+		if err != nil {
+			message := err.Error()
+			if param.IsSensitive && values[name] != "" {
+				message = strings.Replace(message, values[name], "******", -1)
+			}
+			problems = append(problems, fmt.Sprintf(`  param "%s": %s`, name, message))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid params:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// SensitiveValues - Lists the values provided for sensitive params
+func (sd *ServerDefinition) SensitiveValues(values map[string]string) []string {
+	var secrets []string
+	for name, value := range values {
+		param, ok := sd.Params[name]
+		if ok && param.IsSensitive && value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
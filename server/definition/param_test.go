@@ -0,0 +1,154 @@
+package definition_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/virtru/cork/server/definition"
+)
+
+var typed_params_definition_yml = `
+version: 1
+params:
+  dry_run:
+    type: bool
+    default: "false"
+  replicas:
+    type: int
+  environment:
+    type: enum
+    choices: [staging, production]
+  regions:
+    type: list
+    pattern: "[a-z]+-[a-z]+-[0-9]"
+  version:
+    pattern: "v[0-9]+\\.[0-9]+\\.[0-9]+"
+stages:
+  default:
+    - type: command
+      args:
+        command: "deploy {{ param \"environment\" }}"
+`
+
+var bad_enum_definition_yml = `
+version: 1
+params:
+  environment:
+    type: enum
+stages:
+  default:
+    - type: command
+      args:
+        command: deploy
+`
+
+var bad_default_definition_yml = `
+version: 1
+params:
+  replicas:
+    type: int
+    default: "three"
+stages:
+  default:
+    - type: command
+      args:
+        command: deploy
+`
+
+var unknown_param_type_definition_yml = `
+version: 1
+params:
+  replicas:
+    type: float
+stages:
+  default:
+    - type: command
+      args:
+        command: deploy
+`
+
+func TestParamValidateValue(t *testing.T) {
+	boolParam := definition.Param{Type: "bool"}
+	assert.NoError(t, boolParam.ValidateValue("true"))
+	assert.Error(t, boolParam.ValidateValue("maybe"))
+
+	intParam := definition.Param{Type: "int"}
+	assert.NoError(t, intParam.ValidateValue("3"))
+	assert.Error(t, intParam.ValidateValue("3.5"))
+
+	enumParam := definition.Param{Type: "enum", Choices: []string{"staging", "production"}}
+	assert.NoError(t, enumParam.ValidateValue("staging"))
+	assert.Error(t, enumParam.ValidateValue("qa"))
+
+	listParam := definition.Param{Type: "list", Pattern: "[a-z]+"}
+	assert.NoError(t, listParam.ValidateValue("a, b,c"))
+	assert.Error(t, listParam.ValidateValue("a,B"))
+	assert.EqualValues(t, []string{"a", "b", "c"}, definition.ListValues("a, b,,c"))
+
+	// Patterns must match the entire value
+	stringParam := definition.Param{Pattern: "v[0-9]+"}
+	assert.NoError(t, stringParam.ValidateValue("v12"))
+	assert.Error(t, stringParam.ValidateValue("xv12"))
+}
+
+func TestTypedParamsDefinition(t *testing.T) {
+	def, err := definition.LoadFromString(typed_params_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = def.ValidateParamValues(map[string]string{
+		"dry_run":     "true",
+		"replicas":    "2",
+		"environment": "production",
+		"regions":     "us-east-1,eu-west-2",
+		"version":     "v1.2.3",
+	})
+	assert.NoError(t, err)
+
+	err = def.ValidateParamValues(map[string]string{
+		"replicas":    "two",
+		"environment": "qa",
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `param "environment"`)
+		assert.Contains(t, err.Error(), `param "replicas"`)
+	}
+}
+
+func TestBadTypedParamsDefinition(t *testing.T) {
+	_, err := definition.LoadFromString(bad_enum_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "choices")
+	}
+
+	_, err = definition.LoadFromString(bad_default_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "default")
+	}
+
+	_, err = definition.LoadFromString(unknown_param_type_definition_yml)
+	assert.Error(t, err)
+}
+
+func TestSensitiveParamValues(t *testing.T) {
+	pattern := "[a-z]+"
+	def := &definition.ServerDefinition{
+		Params: map[string]definition.Param{
+			"password": {IsSensitive: true, Pattern: pattern},
+			"username": {},
+		},
+	}
+
+	secrets := def.SensitiveValues(map[string]string{
+		"password": "Hunter2",
+		"username": "admin",
+	})
+	assert.EqualValues(t, []string{"Hunter2"}, secrets)
+
+	// Invalid sensitive values are not echoed back
+	err := def.ValidateParamValues(map[string]string{"password": "Hunter2"})
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "Hunter2")
+	}
+}
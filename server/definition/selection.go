@@ -0,0 +1,94 @@
+package definition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StepSelection - Selects the steps of a stage to run. From and Until select a
+// range of steps and Only selects a single step. Steps are selected by name and
+// parallel steps are selected as a whole
+type StepSelection struct {
+	From  string
+	Until string
+	Only  string
+}
+
+// IsEmpty - Checks if every step is selected
+func (ss StepSelection) IsEmpty() bool {
+	return ss.From == "" && ss.Until == "" && ss.Only == ""
+}
+
+func (ss StepSelection) String() string {
+	var selected []string
+	if ss.Only != "" {
+		selected = append(selected, fmt.Sprintf(`only "%s"`, ss.Only))
+	}
+	if ss.From != "" {
+		selected = append(selected, fmt.Sprintf(`from "%s"`, ss.From))
+	}
+	if ss.Until != "" {
+		selected = append(selected, fmt.Sprintf(`until "%s"`, ss.Until))
+	}
+	return strings.Join(selected, " ")
+}
+
+// Select - Selects steps from the resolved steps of a stage
+func (ss StepSelection) Select(stageName string, steps []*Step) ([]*Step, error) {
+	if ss.Only != "" {
+		if ss.From != "" || ss.Until != "" {
+			return nil, fmt.Errorf("Only one step can be selected when running only a step. Remove from and until")
+		}
+		index, err := findSelectedStep(stageName, steps, ss.Only)
+		if err != nil {
+			return nil, err
+		}
+		return steps[index : index+1], nil
+	}
+
+	start := 0
+	end := len(steps)
+	if ss.From != "" {
+		index, err := findSelectedStep(stageName, steps, ss.From)
+		if err != nil {
+			return nil, err
+		}
+		start = index
+	}
+	if ss.Until != "" {
+		index, err := findSelectedStep(stageName, steps, ss.Until)
+		if err != nil {
+			return nil, err
+		}
+		end = index + 1
+	}
+	if start >= end {
+		return nil, fmt.Errorf(`Step "%s" runs after step "%s" in stage "%s"`, ss.From, ss.Until, stageName)
+	}
+	return steps[start:end], nil
+}
+
+func findSelectedStep(stageName string, steps []*Step, name string) (int, error) {
+	for index, step := range steps {
+		if step.ReferenceName() == name {
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf(`Step "%s" is not one of the steps of stage "%s"`, name, stageName)
+}
+
+// stepNames - Lists the names of steps, including the steps of parallel steps
+func stepNames(steps []*Step) map[string]bool {
+	names := map[string]bool{}
+	for _, step := range steps {
+		if step.Name != "" {
+			names[step.Name] = true
+		}
+		for _, branch := range step.Branches {
+			for name := range stepNames(branch) {
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
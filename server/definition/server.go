@@ -5,14 +5,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
 	"gopkg.in/yaml.v2"
 )
 
-const maxDepth = 50
-
 // TemplateRenderer - Interface to user for the template rendering
 type TemplateRenderer interface {
 	Render(templateStr string)
@@ -25,17 +25,23 @@ var StepTypes = map[string]bool{
 	"container": true,
 	"command":   true,
 	"export":    true,
+	"parallel":  true,
+	"script":    true,
 }
 
-type Param struct {
-	Type        string  `yaml:"type"`
-	Default     *string `yaml:"default,omitempty"`
-	Description string  `yaml:"description"`
-	IsSensitive bool    `yaml:"is_sensitive"`
+// Volumes - Defines the named volumes of a cork server and where they are mounted
+type Volumes struct {
+	Names  []string `yaml:"names,omitempty"`
+	Mounts []string `yaml:"mounts,omitempty"`
 }
 
-func (p Param) HasDefault() bool {
-	return p.Default != nil
+func (v Volumes) hasName(name string) bool {
+	for _, volumeName := range v.Names {
+		if volumeName == name {
+			return true
+		}
+	}
+	return false
 }
 
 // ServerDefinition - Defines a cork server
@@ -43,6 +49,7 @@ type ServerDefinition struct {
 	Stages  map[string]Stage `yaml:"stages"`
 	Params  map[string]Param `yaml:"params"`
 	Tags    []string         `yaml:"tags"`
+	Volumes Volumes          `yaml:"volumes"`
 	Version int              `yaml:"version"`
 
 	// Internal data
@@ -83,10 +90,38 @@ func LoadFromBytes(defBytes []byte) (*ServerDefinition, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	sources := mapStepSources(defBytes)
+	for stageName, stage := range def.Stages {
+		setStagePositions(stage, sources[stageName])
+	}
+
 	err = def.Validate()
 	return &def, err
 }
 
+// LoadVolumesFromBytes - Loads only the volumes of a server definition. The rest
+// of the definition is ignored so definitions written for other versions of cork
+// still load. The cork server checks the whole definition when it starts
+func LoadVolumesFromBytes(defBytes []byte) (*ServerDefinition, error) {
+	var volumesDef struct {
+		Volumes Volumes `yaml:"volumes"`
+	}
+	err := yaml.Unmarshal(defBytes, &volumesDef)
+	if err != nil {
+		return nil, err
+	}
+
+	def := &ServerDefinition{
+		Volumes: volumesDef.Volumes,
+	}
+	err = def.validateVolumes()
+	if err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
 func (sd *ServerDefinition) ListStages() []string {
 	stageNames := make([]string, len(sd.Stages))
 	i := 0
@@ -97,41 +132,375 @@ func (sd *ServerDefinition) ListStages() []string {
 	return stageNames
 }
 
+// StepFilter - Decides if a step is included when resolving a stage
+type StepFilter func(step *Step) bool
+
 // ListSteps - Traverses the steps of a stage and resolves everything to a step
 func (sd *ServerDefinition) ListSteps(stageName string) ([]*Step, error) {
-	return sd.resolveSteps(stageName, 0)
+	return sd.resolveSteps(stageName, nil, nil)
+}
+
+// ListStepsWithTags - Resolves the steps of a stage that match the active tags
+func (sd *ServerDefinition) ListStepsWithTags(stageName string, tags []string) ([]*Step, error) {
+	steps, _, err := sd.ListSelectedSteps(stageName, tags, StepSelection{}, nil)
+	return steps, err
+}
+
+// ListSelectedSteps - Resolves the selected steps of a stage that match the active
+// tags. The steps that are not selected don't run so their outputs come from the
+// outputs of an earlier run, keyed by step name. Returns the selected steps and
+// the earlier outputs they may use
+func (sd *ServerDefinition) ListSelectedSteps(stageName string, tags []string, selection StepSelection, previousOutputs map[string]map[string]string) ([]*Step, map[string]map[string]string, error) {
+	filter := func(step *Step) bool {
+		return step.MatchesTags(tags)
+	}
+	steps, err := sd.resolveSteps(stageName, filter, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	steps, err = selection.Select(stageName, steps)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selectedNames := stepNames(steps)
+	outputs := map[string]map[string]string{}
+	for stepName, stepOutputs := range previousOutputs {
+		if !selectedNames[stepName] {
+			outputs[stepName] = stepOutputs
+		}
+	}
+
+	// Filtering may have removed steps that produce outputs used by later steps
+	_, err = sd.walkSteps(stageName, steps, nil, outputs, true)
+	if err != nil {
+		if selection.IsEmpty() {
+			return nil, nil, fmt.Errorf(`Stage "%s" cannot run with tags %v. %v`, stageName, tags, err)
+		}
+		return nil, nil, fmt.Errorf(`Stage "%s" cannot run %s with tags %v. %v`, stageName, selection, tags, err)
+	}
+	return steps, outputs, nil
+}
+
+// StageHooks - The resolved steps that run after the steps of a stage
+type StageHooks struct {
+	OnFailure []*Step
+	Finally   []*Step
+}
+
+// ListHooksWithTags - Resolves the on_failure and finally steps of a stage that
+// match the active tags. Hooks only run for the stage being run, not for stages
+// it calls with stage steps
+func (sd *ServerDefinition) ListHooksWithTags(stageName string, tags []string) (*StageHooks, error) {
+	filter := func(step *Step) bool {
+		return step.MatchesTags(tags)
+	}
+	return sd.resolveHooks(stageName, filter)
 }
 
-func (sd *ServerDefinition) resolveSteps(stageName string, depth int) ([]*Step, error) {
-	if depth > maxDepth {
-		// FIXME. we should detect circular dependencies
-		return nil, fmt.Errorf("Maximum stage recursion reached. You may have circular stage dependencies")
+func (sd *ServerDefinition) resolveHooks(stageName string, filter StepFilter) (*StageHooks, error) {
+	stage, ok := sd.Stages[stageName]
+	if !ok {
+		return nil, fmt.Errorf("Invalid definition. Cannot find stage '%s'", stageName)
 	}
+	path := []string{stageName}
 
+	onFailure, err := sd.resolveStepList(stage.OnFailure, filter, path)
+	if err != nil {
+		return nil, err
+	}
+	finally, err := sd.resolveStepList(stage.Finally, filter, path)
+	if err != nil {
+		return nil, err
+	}
+	return &StageHooks{
+		OnFailure: onFailure,
+		Finally:   finally,
+	}, nil
+}
+
+// resolveSteps - Resolves a stage into the steps that it runs. The path is the
+// chain of stages that led to this stage and is used to report circular references
+func (sd *ServerDefinition) resolveSteps(stageName string, filter StepFilter, path []string) ([]*Step, error) {
 	stage, ok := sd.Stages[stageName]
 	if !ok {
 		return nil, fmt.Errorf("Invalid definition. Cannot find stage '%s'", stageName)
 	}
+	return sd.resolveStepList(stage.Steps, filter, append(path, stageName))
+}
+
+func (sd *ServerDefinition) resolveStepList(stepList []*Step, filter StepFilter, path []string) ([]*Step, error) {
 	var steps []*Step
-	for _, step := range stage {
-		if _, ok := StepTypes[step.Type]; !ok {
-			return nil, fmt.Errorf("Unknown step type: %s", step.Type)
+	for _, step := range stepList {
+		err := checkStepType(step)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil && !filter(step) {
+			continue
 		}
-		if step.Type != "stage" {
+		switch step.Type {
+		case "stage":
+			stageSteps, err := sd.resolveStageStep(step, filter, path)
+			if err != nil {
+				return nil, err
+			}
+			for _, combinationSteps := range stageSteps {
+				steps = append(steps, combinationSteps...)
+			}
+		case "parallel":
+			parallelStep, err := sd.resolveParallelStep(step, filter, path)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, parallelStep)
+		default:
 			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+// checkStepType - Checks that a step has a known type and that only stage steps have a matrix
+func checkStepType(step *Step) error {
+	if _, ok := StepTypes[step.Type]; !ok {
+		return stepError(step, "Unknown step type: %s", step.Type)
+	}
+	if len(step.Matrix) > 0 && step.Type != "stage" {
+		return stepError(step, `Invalid Definition: step "%s" cannot have a matrix. Only stage steps can`, step.ReferenceName())
+	}
+	// Stage steps are replaced by the steps of their stage so a condition or
+	// execution policy on them would be lost
+	if step.Type == "stage" && step.When != "" {
+		return stepError(step, `Invalid Definition: stage step "%s" cannot have a when condition. Set it on the steps of stage "%s"`, step.ReferenceName(), step.Args.Stage)
+	}
+	if step.Type == "stage" && (step.Timeout != "" || step.Retry != nil) {
+		return stepError(step, `Invalid Definition: stage step "%s" cannot have a timeout or retry. Set them on the steps of stage "%s"`, step.ReferenceName(), step.Args.Stage)
+	}
+	return nil
+}
+
+// resolveStageStep - Resolves the stage that a stage step runs. A stage step with a
+// matrix resolves to a copy of the stage's steps for each combination
+func (sd *ServerDefinition) resolveStageStep(step *Step, filter StepFilter, path []string) ([][]*Step, error) {
+	stageName := step.Args.Stage
+	if stageName == "" {
+		return nil, stepError(step, "'stage' step requires a 'stage' argument")
+	}
+	if _, ok := sd.Stages[stageName]; !ok {
+		return nil, stepError(step, "Invalid definition. Cannot find stage '%s'", stageName)
+	}
+	for i, pathStageName := range path {
+		if pathStageName == stageName {
+			cycle := append(append([]string{}, path[i:]...), stageName)
+			return nil, stepError(step, "Invalid Definition: circular stage reference %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	if len(step.Matrix) == 0 {
+		steps, err := sd.resolveSteps(stageName, filter, path)
+		if err != nil {
+			return nil, err
+		}
+		return [][]*Step{steps}, nil
+	}
+
+	combinations, err := matrixCombinations(step)
+	if err != nil {
+		return nil, err
+	}
+	var combinationsSteps [][]*Step
+	for _, combination := range combinations {
+		steps, err := sd.resolveSteps(stageName, filter, path)
+		if err != nil {
+			return nil, err
+		}
+		combinationsSteps = append(combinationsSteps, copyForCombination(steps, combination))
+	}
+	return combinationsSteps, nil
+}
+
+// resolveParallelStep - Resolves each of a parallel step's steps into a branch
+func (sd *ServerDefinition) resolveParallelStep(step *Step, filter StepFilter, path []string) (*Step, error) {
+	if len(step.Steps) == 0 {
+		return nil, stepError(step, "'parallel' step %s requires a list of 'steps'", step.ReferenceName())
+	}
+
+	// Each combination of a matrix runs as its own branch
+	var branches [][]*Step
+	for _, branchStep := range step.Steps {
+		err := checkStepType(branchStep)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil && !filter(branchStep) {
 			continue
 		}
 
-		if step.Args.Stage == "" {
-			return nil, fmt.Errorf("'stage' step requires a 'stage' argument")
+		stepBranches := [][]*Step{{branchStep}}
+		if branchStep.Type == "stage" {
+			stepBranches, err = sd.resolveStageStep(branchStep, filter, path)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, branch := range stepBranches {
+			for _, resolvedStep := range branch {
+				if resolvedStep.Type == "parallel" {
+					return nil, stepError(resolvedStep, "'parallel' step %s cannot contain other 'parallel' steps", step.ReferenceName())
+				}
+			}
+			branches = append(branches, branch)
 		}
-		stageSteps, err := sd.resolveSteps(step.Args.Stage, depth+1)
+	}
+
+	resolvedStep := *step
+	resolvedStep.Branches = branches
+	return &resolvedStep, nil
+}
+
+// stageReferences - Lists the stage steps of a stage, including those run by parallel steps
+func stageReferences(steps []*Step) []*Step {
+	var references []*Step
+	for _, step := range steps {
+		switch step.Type {
+		case "stage":
+			references = append(references, step)
+		case "parallel":
+			references = append(references, stageReferences(step.Steps)...)
+		}
+	}
+	return references
+}
+
+// findStageCycles - Finds circular stage references by walking the graph of stages.
+// Each cycle is reported once. Every stage that reaches a cycle is also returned
+// since those stages cannot be resolved
+func (sd *ServerDefinition) findStageCycles() ([]error, map[string]bool) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	reachesCycle := map[string]bool{}
+	var errs []error
+	var path []string
+
+	markPath := func() {
+		for _, stageName := range path {
+			reachesCycle[stageName] = true
+		}
+	}
+
+	var visit func(stageName string)
+	visit = func(stageName string) {
+		state[stageName] = visiting
+		path = append(path, stageName)
+
+		stage := sd.Stages[stageName]
+		var stageSteps []*Step
+		stageSteps = append(stageSteps, stage.Steps...)
+		stageSteps = append(stageSteps, stage.OnFailure...)
+		stageSteps = append(stageSteps, stage.Finally...)
+		for _, step := range stageReferences(stageSteps) {
+			target := step.Args.Stage
+			if _, ok := sd.Stages[target]; !ok {
+				continue
+			}
+			switch state[target] {
+			case visiting:
+				for i, pathStageName := range path {
+					if pathStageName == target {
+						cycle := append(append([]string{}, path[i:]...), target)
+						errs = append(errs, stepError(step, "Invalid Definition: circular stage reference %s", strings.Join(cycle, " -> ")))
+						break
+					}
+				}
+				markPath()
+			case visited:
+				if reachesCycle[target] {
+					markPath()
+				}
+			default:
+				visit(target)
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[stageName] = visited
+	}
+
+	for _, stageName := range sd.sortedStageNames() {
+		if state[stageName] == 0 {
+			visit(stageName)
+		}
+	}
+	return errs, reachesCycle
+}
+
+func (sd *ServerDefinition) sortedStageNames() []string {
+	stageNames := sd.ListStages()
+	sort.Strings(stageNames)
+	return stageNames
+}
+
+// VolumeNames - Lists the project scoped names of the definition's named volumes
+func (sd *ServerDefinition) VolumeNames(renderer *CorkTemplateRenderer) []string {
+	var volumeNames []string
+	for _, name := range sd.Volumes.Names {
+		volumeNames = append(volumeNames, renderer.VolumeName(name))
+	}
+	return volumeNames
+}
+
+// VolumeMounts - Renders the definition's mounts in the form SOURCE:DESTINATION[:OPTIONS]
+func (sd *ServerDefinition) VolumeMounts(renderer *CorkTemplateRenderer) ([]string, error) {
+	var mounts []string
+	for _, mount := range sd.Volumes.Mounts {
+		renderedMount, err := renderer.Render(mount)
 		if err != nil {
 			return nil, err
 		}
-		steps = append(steps, stageSteps...)
+		splitMount := strings.Split(renderedMount, ":")
+		if len(splitMount) < 2 || len(splitMount) > 3 || splitMount[0] == "" || splitMount[1] == "" {
+			return nil, fmt.Errorf(`Invalid Definition: mount "%s" must be in the form SOURCE:DESTINATION[:OPTIONS]`, mount)
+		}
+		mounts = append(mounts, renderedMount)
 	}
-	return steps, nil
+	return mounts, nil
+}
+
+func (sd *ServerDefinition) validateVolumes() error {
+	declaredVolumes := map[string]bool{}
+	for _, name := range sd.Volumes.Names {
+		if declaredVolumes[name] {
+			return fmt.Errorf(`Invalid Definition: volume "%s" is declared more than once`, name)
+		}
+		declaredVolumes[name] = true
+	}
+
+	renderer := NewTemplateRendererWithOptions(CorkTemplateRendererOptions{
+		WorkDir:     "/work",
+		HostWorkDir: "/host_work",
+		CacheDir:    "/cache",
+	})
+	_, err := sd.VolumeMounts(renderer)
+	if err != nil {
+		return err
+	}
+
+	for _, requiredVar := range renderer.ListRequiredVars() {
+		switch requiredVar.Type {
+		case "volume":
+			if !declaredVolumes[requiredVar.Lookup] {
+				return fmt.Errorf(`Invalid Definition: Volume "%s" is not declared in volumes.names`, requiredVar.Lookup)
+			}
+		default:
+			return fmt.Errorf(`Invalid Definition: mounts may only use volumes and directory variables`)
+		}
+	}
+	return nil
 }
 
 // RequiredUserParamsForStage gathers the required user params for a specific stage
@@ -145,65 +514,177 @@ func (sd *ServerDefinition) RequiredUserParamsForStage(stageName string) ([]stri
 	return requiredUserParams, nil
 }
 
-func (sd *ServerDefinition) walkSteps(stageName string) ([]string, error) {
-	steps, err := sd.resolveSteps(stageName, 0)
-	if err != nil {
-		return nil, err
+// RequiredUserParamsForSteps gathers the required user params for resolved steps
+// and hooks of a stage. Use this when the steps were filtered by tags or selected.
+// Selected steps may use the outputs of an earlier run
+func (sd *ServerDefinition) RequiredUserParamsForSteps(stageName string, steps []*Step, hooks *StageHooks, previousOutputs map[string]map[string]string) ([]string, error) {
+	return sd.walkSteps(stageName, steps, hooks, previousOutputs, true)
+}
+
+// stepsWalk - The state gathered while walking the steps of a stage
+type stepsWalk struct {
+	stageName          string
+	renderer           *CorkTemplateRenderer
+	requiredUserParams map[string]bool
+	usedStepNames      map[string]bool
+	errors             *errorList
+
+	// Set while walking on_failure and finally steps
+	inHooks bool
+
+	// Set when the steps are about to run. Otherwise a stage may use matrix values
+	// that it only gets when a stage step with a matrix runs it
+	running bool
+}
+
+// walkSteps - Validates the steps of a stage and its hooks, if any, and gathers
+// the user params they require. The previous outputs are available to every step
+func (sd *ServerDefinition) walkSteps(stageName string, steps []*Step, hooks *StageHooks, previousOutputs map[string]map[string]string, running bool) ([]string, error) {
+	walk := &stepsWalk{
+		stageName:          stageName,
+		running:            running,
+		renderer:           NewTemplateRenderer(),
+		requiredUserParams: map[string]bool{},
+		usedStepNames:      map[string]bool{},
+		errors:             &errorList{},
 	}
 
-	renderer := NewTemplateRenderer()
-	requiredUserParamsMap := map[string]bool{}
 	availableOutputs := map[string]bool{}
-	usedStepNames := map[string]bool{}
+	for stepName, stepOutputs := range previousOutputs {
+		for outputName := range stepOutputs {
+			availableOutputs[fmt.Sprintf("%s.%s", stepName, outputName)] = true
+		}
+	}
+	sd.walkStepList(walk, steps, availableOutputs)
+
+	if hooks != nil {
+		// Hooks may run after any step so outputs of the stage may be missing. Those
+		// render as empty values
+		walk.inHooks = true
+		sd.walkStepList(walk, hooks.OnFailure, availableOutputs)
+		sd.walkStepList(walk, hooks.Finally, availableOutputs)
+	}
+
+	var requiredUserParams []string
+	for requiredUserParam := range walk.requiredUserParams {
+		_, ok := sd.Params[requiredUserParam]
+		if !ok {
+			walk.errors.Add(fmt.Errorf(`Invalid Definition: Variable "%s" is not defined. All expected variables need to have a definition.`, requiredUserParam))
+			continue
+		}
+		requiredUserParams = append(requiredUserParams, requiredUserParam)
+	}
+	sort.Strings(requiredUserParams)
+
+	err := walk.errors.Err()
+	if err != nil {
+		return nil, err
+	}
+	return requiredUserParams, nil
+}
 
+// walkStepList - Validates a list of steps that run in order. Outputs produced by
+// the steps are added to availableOutputs. An output maps to false if it may be
+// missing because a step with a when condition produces it. Problems are
+// collected on the walk
+func (sd *ServerDefinition) walkStepList(walk *stepsWalk, steps []*Step, availableOutputs map[string]bool) {
+	renderer := walk.renderer
 	for _, step := range steps {
-		log.Debugf("Walking... Stage: %s Step Name: %s\n", stageName, step.Name)
+		log.Debugf("Walking... Stage: %s Step Name: %s\n", walk.stageName, step.Name)
 		if step.Name != "" {
-			used, _ := usedStepNames[step.Name]
+			used, _ := walk.usedStepNames[step.Name]
 			if used {
-				return nil, fmt.Errorf(`Invalid Definition: step names must be unique in a stage. Step "%s" is not unique in stage "%s"`, step.Name, stageName)
+				walk.errors.Add(stepError(step, `Invalid Definition: step names must be unique in a stage. Step "%s" is not unique in stage "%s"`, step.Name, walk.stageName))
 			}
 		}
-		usedStepNames[step.Name] = true
+		walk.usedStepNames[step.Name] = true
 
-		_, err := step.Args.ResolveArgs(renderer)
-		if err != nil {
-			return nil, err
+		walk.errors.Add(step.validateExecutionPolicy())
+		walk.errors.Add(step.validateArgs())
+
+		conditional := step.When != ""
+		if conditional {
+			_, err := renderer.RenderInMatrix(step.When, step.Combination)
+			if err != nil {
+				walk.errors.Add(stepError(step, `Invalid Definition: step "%s" has an invalid when condition. %v`, step.ReferenceName(), err))
+			}
+			// Outputs that may be missing render as empty values which is fine to check
+			sd.checkRequiredVars(walk, step, availableOutputs, true)
 		}
-		requiredVarsForStep := renderer.ListRequiredVars()
 
-		for _, requiredVar := range requiredVarsForStep {
-			switch requiredVar.Type {
-			case "user":
-				requiredUserParamsMap[requiredVar.Lookup] = true
-			case "output":
-				_, ok := availableOutputs[requiredVar.Lookup]
-				if !ok {
-					return nil, fmt.Errorf(`Invalid Definition: Output variable "%s" used before available to step "%s"`, requiredVar.Lookup, step.ReferenceName())
+		if step.Type == "parallel" {
+			// Branches run concurrently so only outputs from before the parallel step
+			// are available to them. Everything they produce is available afterwards
+			var branchesOutputs []map[string]bool
+			for _, branch := range step.Branches {
+				branchOutputs := map[string]bool{}
+				for outputName, always := range availableOutputs {
+					branchOutputs[outputName] = always
 				}
+				sd.walkStepList(walk, branch, branchOutputs)
+				branchesOutputs = append(branchesOutputs, branchOutputs)
 			}
-			renderer.ResetRequiredVarTracker()
+			for _, branchOutputs := range branchesOutputs {
+				for outputName, always := range branchOutputs {
+					if availableOutputs[outputName] {
+						continue
+					}
+					availableOutputs[outputName] = always && !conditional
+				}
+			}
+			continue
+		}
+
+		_, err := step.ResolveArgs(renderer)
+		if err != nil {
+			walk.errors.Add(stepError(step, `Invalid Definition: step "%s" has an invalid template. %v`, step.ReferenceName(), err))
 		}
+		sd.checkRequiredVars(walk, step, availableOutputs, conditional)
 
 		for _, availableOutputName := range step.Outputs {
-			availableOutputs[fmt.Sprintf("%s.%s", step.Name, availableOutputName)] = true
+			availableOutputs[fmt.Sprintf("%s.%s", step.Name, availableOutputName)] = !conditional
 		}
 	}
+}
 
-	var requiredUserParams []string
-
-	for requiredUserParam := range requiredUserParamsMap {
-		_, ok := sd.Params[requiredUserParam]
-		if !ok {
-			return nil, fmt.Errorf(`Invalid Definition: Variable "%s" is not defined. All expected variables need to have a definition.`, requiredUserParam)
+// checkRequiredVars - Checks the template variables a step rendered. Outputs that
+// may be missing are only allowed when allowMissingOutputs is set
+func (sd *ServerDefinition) checkRequiredVars(walk *stepsWalk, step *Step, availableOutputs map[string]bool, allowMissingOutputs bool) {
+	requiredVarsForStep := walk.renderer.ListRequiredVars()
+	walk.renderer.ResetRequiredVarTracker()
+
+	for _, requiredVar := range requiredVarsForStep {
+		switch requiredVar.Type {
+		case "user":
+			walk.requiredUserParams[requiredVar.Lookup] = true
+		case "output":
+			always, ok := availableOutputs[requiredVar.Lookup]
+			if !ok {
+				walk.errors.Add(stepError(step, `Invalid Definition: Output variable "%s" used before available to step "%s"`, requiredVar.Lookup, step.ReferenceName()))
+			} else if !always && !allowMissingOutputs && !walk.inHooks {
+				walk.errors.Add(stepError(step, `Invalid Definition: Output variable "%s" may be missing because the step producing it has a when condition. Step "%s" needs a when condition to use it`, requiredVar.Lookup, step.ReferenceName()))
+			}
+		case "volume":
+			if !sd.Volumes.hasName(requiredVar.Lookup) {
+				walk.errors.Add(stepError(step, `Invalid Definition: Volume "%s" used by step "%s" is not declared in volumes.names`, requiredVar.Lookup, step.ReferenceName()))
+			}
+		case "matrix":
+			if step.Combination == nil && !walk.running {
+				continue
+			}
+			if _, ok := step.Combination.Value(requiredVar.Lookup); !ok {
+				walk.errors.Add(stepError(step, `Invalid Definition: Matrix value "%s" used by step "%s" is not set by a matrix`, requiredVar.Lookup, step.ReferenceName()))
+			}
+		case "failure":
+			if !walk.inHooks {
+				walk.errors.Add(stepError(step, `Invalid Definition: %s used by step "%s" is only available to on_failure and finally steps`, requiredVar.Lookup, step.ReferenceName()))
+			}
 		}
-		requiredUserParams = append(requiredUserParams, requiredUserParam)
 	}
-
-	return requiredUserParams, nil
 }
 
-// Validate validates a definition file by running through the stages
+// Validate validates a definition file by running through the stages. Every
+// problem that is found is returned together
 func (sd *ServerDefinition) Validate() error {
 	if sd.Version == 0 {
 		return fmt.Errorf("Invalid Definition: version must be specified")
@@ -213,12 +694,35 @@ func (sd *ServerDefinition) Validate() error {
 		return fmt.Errorf("Invalid Definition: only version 1 is support")
 	}
 
-	for stageName := range sd.Stages {
-		requiredUserParams, err := sd.walkSteps(stageName)
+	errs := &errorList{}
+	errs.Add(sd.validateParams())
+	errs.Add(sd.validateVolumes())
+
+	cycleErrs, reachesCycle := sd.findStageCycles()
+	for _, err := range cycleErrs {
+		errs.Add(err)
+	}
+
+	for _, stageName := range sd.sortedStageNames() {
+		if reachesCycle[stageName] {
+			continue
+		}
+		steps, err := sd.resolveSteps(stageName, nil, nil)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		hooks, err := sd.resolveHooks(stageName, nil)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		requiredUserParams, err := sd.walkSteps(stageName, steps, hooks, nil, false)
 		if err != nil {
-			return err
+			errs.Add(err)
+			continue
 		}
 		sd.requiredUserParamsByStage[stageName] = requiredUserParams
 	}
-	return nil
+	return errs.Err()
 }
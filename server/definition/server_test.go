@@ -4,6 +4,7 @@ package definition_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/virtru/cork/server/definition"
 
@@ -356,3 +357,1278 @@ func TestBadDefLoadFromStringForVersionCheck(t *testing.T) {
 		}
 	}
 }
+
+var tagged_definition_yml = `
+version: 1
+
+stages:
+  build:
+    - name: lint
+      type: command
+      args:
+        command: lint
+      match_tags:
+        - ci
+
+    - name: build_container
+      type: command
+      args:
+        command: build
+      outputs:
+        - app_image
+
+    - name: cache_warmup
+      type: command
+      args:
+        command: cache_warmup
+      skip_tags:
+        - ci
+
+    - name: version
+      type: command
+      args:
+        command: version
+      outputs:
+        - version
+      match_tags:
+        - release
+
+    - name: publish
+      type: command
+      args:
+        command: publish
+        params:
+          app_image: '{{ output "build_container.app_image" }}'
+          version: '{{ output "version.version" }}'
+      match_tags:
+        - release
+
+  default:
+    - type: stage
+      args:
+        stage: build
+
+  release:
+    - type: stage
+      args:
+        stage: build
+
+    - name: publish_notes
+      type: command
+      args:
+        command: publish_notes
+        params:
+          version: '{{ output "version.version" }}'
+`
+
+func stepNamesOf(steps []*definition.Step) []string {
+	var stepNames []string
+	for _, step := range steps {
+		stepNames = append(stepNames, step.Name)
+	}
+	return stepNames
+}
+
+func TestListStepsWithTags(t *testing.T) {
+	def, err := definition.LoadFromString(tagged_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	steps, err := def.ListStepsWithTags("default", nil)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"build_container", "cache_warmup"}, stepNamesOf(steps))
+	}
+
+	steps, err = def.ListStepsWithTags("default", []string{"ci"})
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"lint", "build_container"}, stepNamesOf(steps))
+	}
+
+	steps, err = def.ListStepsWithTags("default", []string{"ci", "release"})
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"lint", "build_container", "version", "publish"}, stepNamesOf(steps))
+	}
+
+	// Without tags all of the steps are listed
+	steps, err = def.ListSteps("default")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 5, len(steps))
+	}
+}
+
+func TestListSelectedSteps(t *testing.T) {
+	def, err := definition.LoadFromString(tagged_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tags := []string{"ci", "release"}
+	previousOutputs := map[string]map[string]string{
+		"build_container": {"app_image": "app:1"},
+		"version":         {"version": "v1.0.0"},
+	}
+
+	steps, outputs, err := def.ListSelectedSteps("default", tags, definition.StepSelection{From: "version"}, previousOutputs)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"version", "publish"}, stepNamesOf(steps))
+		// Outputs of selected steps come from this run instead
+		assert.Equal(t, map[string]map[string]string{
+			"build_container": {"app_image": "app:1"},
+		}, outputs)
+	}
+
+	steps, _, err = def.ListSelectedSteps("default", tags, definition.StepSelection{From: "build_container", Until: "version"}, nil)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"build_container", "version"}, stepNamesOf(steps))
+	}
+
+	steps, _, err = def.ListSelectedSteps("default", tags, definition.StepSelection{Only: "publish"}, previousOutputs)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"publish"}, stepNamesOf(steps))
+	}
+}
+
+func TestBadSelectedSteps(t *testing.T) {
+	def, err := definition.LoadFromString(tagged_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tags := []string{"ci", "release"}
+
+	// Without an earlier run the outputs used by publish are missing
+	_, _, err = def.ListSelectedSteps("default", tags, definition.StepSelection{Only: "publish"}, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "build_container.app_image")
+	}
+
+	_, _, err = def.ListSelectedSteps("default", tags, definition.StepSelection{From: "deploy"}, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Step "deploy" is not one of the steps of stage "default"`)
+	}
+
+	// cache_warmup is skipped with the ci tag
+	_, _, err = def.ListSelectedSteps("default", tags, definition.StepSelection{Until: "cache_warmup"}, nil)
+	assert.Error(t, err)
+
+	_, _, err = def.ListSelectedSteps("default", tags, definition.StepSelection{From: "version", Until: "lint"}, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Step "version" runs after step "lint"`)
+	}
+
+	_, _, err = def.ListSelectedSteps("default", tags, definition.StepSelection{From: "lint", Only: "publish"}, nil)
+	assert.Error(t, err)
+}
+
+func TestListStepsWithTagsMissingOutputs(t *testing.T) {
+	def, err := definition.LoadFromString(tagged_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = def.ListStepsWithTags("release", []string{"release"})
+	assert.NoError(t, err)
+
+	// "publish_notes" needs an output from a step that only runs for "release"
+	_, err = def.ListStepsWithTags("release", []string{"ci"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "version.version")
+	}
+}
+
+var volumes_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - name: install
+      type: container
+      args:
+        image: node:8
+        command: npm install
+
+volumes:
+  names:
+    - node_modules_cache
+  mounts:
+    - '{{ volumes "node_modules_cache" }}:{{ WORK_DIR }}/node_modules'
+`
+
+var undeclared_volume_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - name: install
+      type: container
+      args:
+        image: node:8
+
+volumes:
+  mounts:
+    - '{{ volumes "node_modules_cache" }}:{{ WORK_DIR }}/node_modules'
+`
+
+var invalid_mount_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - name: install
+      type: container
+      args:
+        image: node:8
+
+volumes:
+  names:
+    - node_modules_cache
+  mounts:
+    - '{{ volumes "node_modules_cache" }}'
+`
+
+func TestVolumesDefinition(t *testing.T) {
+	def, err := definition.LoadFromString(volumes_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir:      "/work",
+		VolumePrefix: "cork-1234-",
+	})
+	assert.EqualValues(t, []string{"cork-1234-node_modules_cache"}, def.VolumeNames(renderer))
+
+	mounts, err := def.VolumeMounts(renderer)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"cork-1234-node_modules_cache:/work/node_modules"}, mounts)
+	}
+}
+
+var newer_volumes_definition_yml = `
+version: 2
+
+stages:
+  default:
+    - name: install
+      type: some_future_type
+      args:
+        image: node:8
+
+volumes:
+  names:
+    - node_modules_cache
+  mounts:
+    - '{{ volumes "node_modules_cache" }}:{{ WORK_DIR }}/node_modules'
+`
+
+func TestLoadVolumes(t *testing.T) {
+	// Only the volumes are read so steps this version doesn't know are fine
+	def, err := definition.LoadVolumesFromBytes([]byte(newer_volumes_definition_yml))
+	if !assert.NoError(t, err) {
+		return
+	}
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir:      "/work",
+		VolumePrefix: "cork-1234-",
+	})
+	mounts, err := def.VolumeMounts(renderer)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"cork-1234-node_modules_cache:/work/node_modules"}, mounts)
+	}
+
+	_, err = definition.LoadVolumesFromBytes([]byte(undeclared_volume_definition_yml))
+	assert.Error(t, err)
+}
+
+func TestBadVolumesDefinition(t *testing.T) {
+	_, err := definition.LoadFromString(undeclared_volume_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "node_modules_cache")
+	}
+
+	_, err = definition.LoadFromString(invalid_mount_definition_yml)
+	assert.Error(t, err)
+}
+
+var parallel_definition_yml = `
+version: 1
+
+stages:
+  security:
+    - name: audit
+      type: command
+      args:
+        command: audit
+    - name: scan
+      type: command
+      args:
+        command: scan
+      outputs:
+        - report
+
+  default:
+    - name: install
+      type: command
+      args:
+        command: install
+      outputs:
+        - cache_key
+
+    - name: checks
+      type: parallel
+      concurrency: 2
+      steps:
+        - name: lint
+          type: command
+          args:
+            command: lint
+            params:
+              cache_key: '{{ output "install.cache_key" }}'
+        - name: unit
+          type: command
+          args:
+            command: unit
+          match_tags:
+            - ci
+        - type: stage
+          args:
+            stage: security
+
+    - name: publish
+      type: command
+      args:
+        command: publish
+        params:
+          report: '{{ output "scan.report" }}'
+`
+
+var parallel_sibling_output_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - type: parallel
+      steps:
+        - name: build
+          type: command
+          args:
+            command: build
+          outputs:
+            - app_image
+        - name: test
+          type: command
+          args:
+            command: test
+            params:
+              app_image: '{{ output "build.app_image" }}'
+`
+
+var nested_parallel_definition_yml = `
+version: 1
+
+stages:
+  checks:
+    - type: parallel
+      steps:
+        - name: lint
+          type: command
+          args:
+            command: lint
+
+  default:
+    - type: parallel
+      steps:
+        - type: stage
+          args:
+            stage: checks
+`
+
+func TestParallelDefinition(t *testing.T) {
+	def, err := definition.LoadFromString(parallel_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.EqualValues(t, []string{"install", "checks", "publish"}, stepNamesOf(steps))
+
+	checks := steps[1]
+	assert.Equal(t, 2, checks.Concurrency)
+	if assert.Equal(t, 3, len(checks.Branches)) {
+		assert.EqualValues(t, []string{"lint"}, stepNamesOf(checks.Branches[0]))
+		assert.EqualValues(t, []string{"unit"}, stepNamesOf(checks.Branches[1]))
+		assert.EqualValues(t, []string{"audit", "scan"}, stepNamesOf(checks.Branches[2]))
+	}
+
+	// Tags filter the steps inside of a parallel step
+	steps, err = def.ListStepsWithTags("default", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, len(steps[1].Branches))
+	}
+}
+
+func TestBadParallelDefinition(t *testing.T) {
+	_, err := definition.LoadFromString(parallel_sibling_output_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "build.app_image")
+	}
+
+	_, err = definition.LoadFromString(nested_parallel_definition_yml)
+	assert.Error(t, err)
+}
+
+var retry_definition_yml = `
+version: 1
+stages:
+  default:
+    - name: integration
+      type: command
+      timeout: 10m
+      retry:
+        attempts: 3
+        backoff: 5s
+        on_exit_codes: [1, 75]
+      args:
+        command: integration
+    - name: lint
+      type: command
+      args:
+        command: lint
+`
+
+var bad_timeout_definition_yml = `
+version: 1
+stages:
+  default:
+    - name: integration
+      type: command
+      timeout: forever
+      args:
+        command: integration
+`
+
+var bad_retry_definition_yml = `
+version: 1
+stages:
+  default:
+    - name: integration
+      type: command
+      retry:
+        attempts: 0
+      args:
+        command: integration
+`
+
+var parallel_retry_definition_yml = `
+version: 1
+stages:
+  default:
+    - name: checks
+      type: parallel
+      timeout: 1m
+      steps:
+        - type: command
+          args:
+            command: lint
+`
+
+var stage_retry_definition_yml = `
+version: 1
+stages:
+  integration:
+    - name: integration
+      type: command
+      args:
+        command: integration
+  default:
+    - type: stage
+      retry:
+        attempts: 2
+      args:
+        stage: integration
+`
+
+func TestRetryDefinition(t *testing.T) {
+	def, err := definition.LoadFromString(retry_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	integration := steps[0]
+	timeout, err := integration.TimeoutDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, timeout)
+	assert.Equal(t, 3, integration.Retry.MaxAttempts())
+	backoff, err := integration.Retry.BackoffDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, backoff)
+	assert.True(t, integration.Retry.RetriesExitCode(75))
+	assert.False(t, integration.Retry.RetriesExitCode(2))
+	assert.False(t, integration.Retry.RetriesTimeout())
+
+	// Steps without a policy run once with no timeout
+	lint := steps[1]
+	timeout, err = lint.TimeoutDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), timeout)
+	assert.Equal(t, 1, lint.Retry.MaxAttempts())
+	assert.False(t, lint.Retry.RetriesExitCode(1))
+}
+
+func TestBadRetryDefinition(t *testing.T) {
+	_, err := definition.LoadFromString(bad_timeout_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid timeout")
+	}
+
+	_, err = definition.LoadFromString(bad_retry_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "retry attempts")
+	}
+
+	_, err = definition.LoadFromString(parallel_retry_definition_yml)
+	assert.Error(t, err)
+
+	// The steps of a stage step replace it so it cannot have a policy
+	_, err = definition.LoadFromString(stage_retry_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `stage step "type:stage" cannot have a timeout or retry`)
+	}
+}
+
+var stage_params_definition_yml = `
+version: 1
+params:
+  test_filter:
+    description: Which tests to run
+  registry_password:
+    is_sensitive: true
+stages:
+  test:
+    - type: command
+      args:
+        command: 'test {{ param "test_filter" }}'
+
+  push:
+    - type: command
+      args:
+        command: 'push {{ param "registry_password" }}'
+      match_tags:
+        - release
+
+  release:
+    - type: stage
+      args:
+        stage: test
+    - type: stage
+      args:
+        stage: push
+`
+
+func TestRequiredUserParamsForSteps(t *testing.T) {
+	def, err := definition.LoadFromString(stage_params_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	requiredParams, err := def.RequiredUserParamsForStage("test")
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"test_filter"}, requiredParams)
+	}
+
+	// Params used by nested stages are required
+	requiredParams, err = def.RequiredUserParamsForStage("release")
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"registry_password", "test_filter"}, requiredParams)
+	}
+
+	// Params of steps dropped by tags are not required
+	steps, err := def.ListStepsWithTags("release", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	requiredParams, err = def.RequiredUserParamsForSteps("release", steps, nil, nil)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"test_filter"}, requiredParams)
+	}
+
+	err = definition.CheckParamValuesProvided(requiredParams, map[string]string{"test_filter": "unit"})
+	assert.NoError(t, err)
+
+	err = definition.CheckParamValuesProvided([]string{"registry_password", "test_filter"}, map[string]string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "registry_password, test_filter")
+	}
+}
+
+var cycle_with_position_definition_yml = `
+version: 1
+
+stages:
+  build:
+    - type: command
+      args:
+        command: build
+    - type: stage
+      args:
+        stage: validate
+
+  validate:
+    - type: stage
+      args:
+        stage: build
+
+  default:
+    - type: stage
+      args:
+        stage: build
+`
+
+var many_errors_definition_yml = `
+version: 1
+
+params:
+  replicas:
+    type: int
+    default: "three"
+
+stages:
+  build:
+    - name: build
+      type: command
+      args:
+        command: build
+    - name: publish
+      type: command
+      args:
+        command: publish
+        params:
+          image: '{{ output "build.image" }}'
+    - name: build
+      type: command
+      args:
+        command: build_again
+
+  checks:
+    - name: lint
+      type: parallel
+      steps:
+        - type: command
+          args:
+            command: lint
+          retry:
+            attempts: 0
+`
+
+func TestCircularStageErrors(t *testing.T) {
+	_, err := definition.LoadFromString(cycle_with_position_definition_yml)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	// The cycle is reported once with the step that closes it
+	assert.Equal(t, "Invalid Definition: circular stage reference build -> validate -> build (line 14, column 5)", err.Error())
+}
+
+func TestValidateReturnsAllErrors(t *testing.T) {
+	_, err := definition.LoadFromString(many_errors_definition_yml)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	errs, ok := err.(definition.DefinitionErrors)
+	if !assert.True(t, ok, "expected all of the errors") {
+		return
+	}
+	assert.Equal(t, 4, len(errs))
+
+	message := err.Error()
+	assert.Contains(t, message, `default for param "replicas" is invalid`)
+	assert.Contains(t, message, `Output variable "build.image" used before available to step "publish" (line 15, column 5)`)
+	assert.Contains(t, message, `Step "build" is not unique in stage "build" (line 21, column 5)`)
+	assert.Contains(t, message, `retry attempts for step "type:command" must be at least 1 (line 30, column 9)`)
+}
+
+func TestStepPositions(t *testing.T) {
+	def, err := definition.LoadFromString(parallel_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, step := range steps {
+		assert.NotNil(t, step.Position(), "step %s should have a position", step.ReferenceName())
+	}
+	for _, branch := range steps[1].Branches {
+		assert.NotNil(t, branch[0].Position())
+	}
+}
+
+var flow_steps_definition_yml = `
+version: 1
+
+stages:
+  flow: [{name: a, type: command, args: {command: a}}, {name: b, type: command, args: {command: b}}]
+  flow_items:
+    - {name: c, type: command, args: {command: c}}
+    - name: d
+      type: command
+      args:
+        command: d
+`
+
+var aliased_steps_definition_yml = `
+version: 1
+
+stages:
+  build: &build_steps
+    - name: build
+      type: command
+      args:
+        command: build
+  default: *build_steps
+  release:
+    - &publish
+      name: publish
+      type: command
+      args:
+        command: publish
+    - <<: *publish
+      name: announce
+`
+
+var block_scalar_steps_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - name: lint
+      type: command
+      args:
+        command: |
+          - name: not_a_step
+          steps:
+          - also_not_a_step
+    - name: "test" # runs the tests
+      type: command
+      args:
+        command: test
+  quoted:
+    - name: a
+      type: command
+      args:
+        command: "echo
+    - name: b
+      type: command"
+    - name: c
+      type: command
+      args:
+        command: c
+`
+
+func TestStepPositionsOfOtherYAMLForms(t *testing.T) {
+	positionsOf := func(def *definition.ServerDefinition, stageName string) []*definition.SourcePosition {
+		var positions []*definition.SourcePosition
+		for _, step := range def.Stages[stageName].Steps {
+			positions = append(positions, step.Position())
+		}
+		return positions
+	}
+
+	def, err := definition.LoadFromString(flow_steps_definition_yml)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []*definition.SourcePosition{nil, nil}, positionsOf(def, "flow"))
+		assert.Equal(t, []*definition.SourcePosition{nil, nil}, positionsOf(def, "flow_items"))
+	}
+
+	def, err = definition.LoadFromString(aliased_steps_definition_yml)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []*definition.SourcePosition{{Line: 6, Column: 5}}, positionsOf(def, "build"))
+		assert.Equal(t, []*definition.SourcePosition{nil}, positionsOf(def, "default"))
+		assert.Equal(t, []*definition.SourcePosition{nil, nil}, positionsOf(def, "release"))
+	}
+
+	def, err = definition.LoadFromString(block_scalar_steps_definition_yml)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []*definition.SourcePosition{{Line: 6, Column: 5}, {Line: 13, Column: 5}}, positionsOf(def, "default"))
+		assert.Equal(t, []*definition.SourcePosition{nil, nil}, positionsOf(def, "quoted"))
+	}
+}
+
+var described_stages_definition_yml = `
+version: 1
+
+stages:
+  build:
+    description: Builds the app image
+    steps:
+      - name: build_container
+        type: command
+        args:
+          command: build_container
+
+  default:
+    - type: stage
+      args:
+        stage: build
+`
+
+func TestDescribedStages(t *testing.T) {
+	def, err := definition.LoadFromString(described_stages_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "Builds the app image", def.Stages["build"].Description)
+	assert.Equal(t, "", def.Stages["default"].Description)
+
+	steps, err := def.ListSteps("default")
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(steps)) {
+		assert.Equal(t, "build_container", steps[0].Name)
+		assert.Equal(t, &definition.SourcePosition{Line: 8, Column: 7}, steps[0].Position())
+	}
+}
+
+var hooks_definition_yml = `
+version: 1
+
+params:
+  db_name:
+    description: The test database
+  webhook:
+    description: Where failures are reported
+
+stages:
+  cleanup:
+    - name: drop_db
+      type: command
+      args:
+        command: 'drop_db {{ param "db_name" }}'
+
+  test:
+    description: Runs the tests against a database
+    steps:
+      - name: start_db
+        type: command
+        args:
+          command: start_db
+        outputs:
+          - container_id
+      - name: unit
+        type: command
+        args:
+          command: test
+    on_failure:
+      - name: notify
+        type: command
+        args:
+          command: 'notify {{ param "webhook" }} {{ FAILED_STEP }} {{ FAILED_STEP_ERROR }}'
+    finally:
+      - name: stop_db
+        type: command
+        args:
+          command: 'stop_db {{ output "start_db.container_id" }}'
+      - type: stage
+        args:
+          stage: cleanup
+`
+
+var failure_outside_hooks_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - name: notify
+      type: command
+      args:
+        command: 'notify {{ FAILED_STEP }}'
+`
+
+func TestStageHooks(t *testing.T) {
+	def, err := definition.LoadFromString(hooks_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	hooks, err := def.ListHooksWithTags("test", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.EqualValues(t, []string{"notify"}, stepNamesOf(hooks.OnFailure))
+	assert.EqualValues(t, []string{"stop_db", "drop_db"}, stepNamesOf(hooks.Finally))
+	assert.Equal(t, &definition.SourcePosition{Line: 31, Column: 7}, hooks.OnFailure[0].Position())
+
+	// Params used by hooks are required
+	requiredParams, err := def.RequiredUserParamsForStage("test")
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"db_name", "webhook"}, requiredParams)
+	}
+
+	// Failures render as plain text. Error messages usually quote the step
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		UserParams: map[string]string{"webhook": "https://hooks.example.com/a?b=1&c=2"},
+	})
+	renderer.SetFailure("unit", fmt.Errorf(`Step "unit" timed out after 1m & <x>`))
+	notify, err := renderer.Render(hooks.OnFailure[0].Args.Command)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `notify https://hooks.example.com/a?b=1&c=2 unit Step "unit" timed out after 1m & <x>`, notify)
+	}
+
+	_, err = definition.LoadFromString(failure_outside_hooks_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "only available to on_failure and finally steps")
+	}
+}
+
+var conditional_definition_yml = `
+version: 1
+
+params:
+  publish:
+    type: bool
+    default: "false"
+
+stages:
+  default:
+    - name: detect
+      type: command
+      args:
+        command: detect_changes
+      outputs:
+        - changed
+
+    - name: migrate
+      type: command
+      when: '{{ output "detect.changed" }}'
+      args:
+        command: migrate
+      outputs:
+        - version
+
+    - name: report
+      type: command
+      when: '{{ output "migrate.version" }}'
+      args:
+        command: 'report {{ output "migrate.version" }}'
+
+    - name: push
+      type: command
+      when: '{{ param "publish" }}'
+      args:
+        command: push
+`
+
+var conditional_output_used_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - name: migrate
+      type: command
+      when: "false"
+      args:
+        command: migrate
+      outputs:
+        - version
+
+    - name: report
+      type: command
+      args:
+        command: 'report {{ output "migrate.version" }}'
+`
+
+var conditional_stage_step_definition_yml = `
+version: 1
+
+params:
+  publish:
+    type: bool
+
+stages:
+  publish:
+    - name: push
+      type: command
+      args:
+        command: push
+  default:
+    - type: stage
+      when: '{{ param "publish" }}'
+      args:
+        stage: publish
+`
+
+func TestConditionalSteps(t *testing.T) {
+	def, err := definition.LoadFromString(conditional_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	requiredParams, err := def.RequiredUserParamsForStage("default")
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"publish"}, requiredParams)
+	}
+
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		UserParams: map[string]string{"publish": "true"},
+	})
+	run, err := steps[1].ShouldRun(renderer)
+	assert.NoError(t, err)
+	assert.False(t, run, "migrate should not run without changes")
+
+	renderer.AddOutput("detect", "changed", "db/schema.sql")
+	run, err = steps[1].ShouldRun(renderer)
+	assert.NoError(t, err)
+	assert.True(t, run)
+
+	run, err = steps[3].ShouldRun(renderer)
+	assert.NoError(t, err)
+	assert.True(t, run)
+
+	// Steps without a condition always run
+	run, err = steps[0].ShouldRun(renderer)
+	assert.NoError(t, err)
+	assert.True(t, run)
+
+	_, err = definition.LoadFromString(conditional_output_used_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `"migrate.version" may be missing`)
+	}
+
+	// The steps of a stage step replace it so it cannot have a condition
+	_, err = definition.LoadFromString(conditional_stage_step_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `stage step "type:stage" cannot have a when condition`)
+	}
+}
+
+func TestIsTruthy(t *testing.T) {
+	for _, value := range []string{"true", "yes", "1", "anything", " True "} {
+		assert.True(t, definition.IsTruthy(value), value)
+	}
+	for _, value := range []string{"", " ", "false", "FALSE", "no", "off", "0"} {
+		assert.False(t, definition.IsTruthy(value), value)
+	}
+}
+
+var matrix_definition_yml = `
+version: 1
+
+stages:
+  build:
+    - name: compile
+      type: command
+      args:
+        command: 'compile {{ matrix "node" }}'
+      outputs:
+        - artifact
+
+    - name: package
+      type: command
+      args:
+        command: 'package {{ output "compile.artifact" }}'
+
+  default:
+    - name: build_all
+      type: stage
+      matrix:
+        node: [4, 6]
+        os: [alpine]
+      args:
+        stage: build
+
+    - name: publish
+      type: command
+      args:
+        command: 'publish {{ output "compile[node=6,os=alpine].artifact" }}'
+
+  concurrent:
+    - name: all
+      type: parallel
+      steps:
+        - type: stage
+          matrix:
+            node: ["8.1", "10"]
+          args:
+            stage: build
+`
+
+var bad_matrix_definition_yml = `
+version: 1
+
+stages:
+  build:
+    - name: compile
+      type: command
+      args:
+        command: 'compile {{ matrix "runtime" }}'
+
+  default:
+    - name: build_all
+      type: stage
+      matrix:
+        node: [4, 6]
+      args:
+        stage: build
+
+  lint:
+    - name: lint
+      type: command
+      matrix:
+        node: [4]
+      args:
+        command: lint
+`
+
+func TestMatrixStageSteps(t *testing.T) {
+	def, err := definition.LoadFromString(matrix_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var names []string
+	for _, step := range steps {
+		names = append(names, step.Name)
+	}
+	assert.EqualValues(t, []string{
+		"compile[node=4,os=alpine]",
+		"package[node=4,os=alpine]",
+		"compile[node=6,os=alpine]",
+		"package[node=6,os=alpine]",
+		"publish",
+	}, names)
+
+	renderer := definition.NewTemplateRenderer()
+	renderer.AddOutput("compile[node=4,os=alpine]", "artifact", "app-4.tar")
+	renderer.AddOutput("compile[node=6,os=alpine]", "artifact", "app-6.tar")
+
+	args, err := steps[0].ResolveArgs(renderer)
+	assert.NoError(t, err)
+	assert.Equal(t, "compile 4", args.Command)
+
+	args, err = steps[1].ResolveArgs(renderer)
+	assert.NoError(t, err)
+	assert.Equal(t, "package app-4.tar", args.Command)
+
+	args, err = steps[3].ResolveArgs(renderer)
+	assert.NoError(t, err)
+	assert.Equal(t, "package app-6.tar", args.Command)
+
+	args, err = steps[4].ResolveArgs(renderer)
+	assert.NoError(t, err)
+	assert.Equal(t, "publish app-6.tar", args.Command)
+
+	assert.Equal(t, "build_all[node=6,os=alpine]", steps[2].Combination.Name())
+
+	// Each combination in a parallel step runs as its own branch
+	steps, err = def.ListSteps("concurrent")
+	if assert.NoError(t, err) && assert.Len(t, steps, 1) && assert.Len(t, steps[0].Branches, 2) {
+		assert.Equal(t, "compile[node=8.1]", steps[0].Branches[0][0].Name)
+		assert.Equal(t, "package[node=10]", steps[0].Branches[1][1].Name)
+
+		renderer.AddOutput("compile[node=8.1]", "artifact", "app-8.tar")
+		args, err = steps[0].Branches[0][1].ResolveArgs(renderer)
+		assert.NoError(t, err)
+		assert.Equal(t, "package app-8.tar", args.Command)
+	}
+
+	// A stage that uses matrix values cannot run without a matrix
+	_, err = def.ListStepsWithTags("build", nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Matrix value "node" used by step "compile" is not set by a matrix`)
+	}
+}
+
+func TestBadMatrixStageSteps(t *testing.T) {
+	_, err := definition.LoadFromString(bad_matrix_definition_yml)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), `Matrix value "runtime" used by step "compile[node=4]" is not set by a matrix`)
+	assert.Contains(t, err.Error(), `step "lint" cannot have a matrix. Only stage steps can`)
+}
+
+var script_definition_yml = `
+version: 1
+
+params:
+  target:
+    default: "dist"
+
+stages:
+  default:
+    - name: greet
+      type: script
+      args:
+        shell: bash -e
+        script: |
+          mkdir -p {{ param "target" }}
+          echo "built" > $CORK_OUTPUTS_DIR/status
+      outputs:
+        - status
+
+    - name: report
+      type: script
+      args:
+        script: echo {{ output "greet.status" }}
+`
+
+var bad_script_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - name: empty
+      type: script
+      args:
+        shell: bash
+`
+
+func TestScriptSteps(t *testing.T) {
+	def, err := definition.LoadFromString(script_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	requiredParams, err := def.RequiredUserParamsForStage("default")
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, []string{"target"}, requiredParams)
+	}
+
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		UserParams: map[string]string{"target": "out"},
+	})
+	args, err := steps[0].ResolveArgs(renderer)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bash -e", args.Shell)
+		assert.Equal(t, "mkdir -p out\necho \"built\" > $CORK_OUTPUTS_DIR/status\n", args.Script)
+	}
+
+	_, err = definition.LoadFromString(bad_script_definition_yml)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `script step "empty" requires a 'script' argument`)
+	}
+}
@@ -0,0 +1,257 @@
+package definition
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SourcePosition - The line and column of something in a definition file
+type SourcePosition struct {
+	Line   int
+	Column int
+}
+
+// stepSource - Where a step is defined along with the steps nested in it. Name
+// and Type are the values written for the step, used to check that the step was
+// located correctly
+type stepSource struct {
+	Position SourcePosition
+	Name     string
+	Type     string
+	Steps    []*stepSource
+}
+
+// stageSource - Where the steps of a stage and its hooks are defined
+type stageSource struct {
+	Steps     []*stepSource
+	OnFailure []*stepSource
+	Finally   []*stepSource
+}
+
+type sourceLine struct {
+	Number int
+	Indent int
+	Text   string
+}
+
+func (l sourceLine) isItem() bool {
+	return l.Text == "-" || strings.HasPrefix(l.Text, "- ")
+}
+
+func (l sourceLine) isKey(key string) bool {
+	return l.Text == key+":" || strings.HasPrefix(l.Text, key+": ")
+}
+
+// keyValue - Splits a "key: value" line. Quotes and comments are removed from
+// simple scalar values. Anything else, like an alias or a flow collection, is returned
+// as it is written
+func keyValue(text string) (string, string, bool) {
+	index := strings.Index(text, ":")
+	if index <= 0 || (index+1 < len(text) && text[index+1] != ' ') {
+		return "", "", false
+	}
+	key := text[:index]
+	value := strings.TrimSpace(text[index+1:])
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		end := strings.IndexByte(value[1:], value[0]) + 1
+		rest := strings.TrimSpace(value[end+1:])
+		if end > 0 && (rest == "" || strings.HasPrefix(rest, "#")) {
+			value = value[1:end]
+		}
+		return key, value, true
+	}
+	if comment := strings.Index(value, " #"); comment >= 0 {
+		value = strings.TrimSpace(value[:comment])
+	}
+	return key, value, true
+}
+
+// stageKey - Gets the stage name from a "name:" line
+func (l sourceLine) stageKey() (string, bool) {
+	if l.isItem() {
+		return "", false
+	}
+	index := strings.Index(l.Text, ":")
+	if index <= 0 {
+		return "", false
+	}
+	return strings.Trim(l.Text[:index], `"'`), true
+}
+
+// sourceLines - Splits a definition into its lines without blank lines or comments
+func sourceLines(defBytes []byte) []sourceLine {
+	var lines []sourceLine
+	for i, rawLine := range bytes.Split(defBytes, []byte("\n")) {
+		line := strings.TrimRight(string(rawLine), " \t\r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		lines = append(lines, sourceLine{
+			Number: i + 1,
+			Indent: len(line) - len(text),
+			Text:   text,
+		})
+	}
+	return lines
+}
+
+// mapStepSources - Finds where the steps of each stage are defined. This only
+// understands block style YAML. Steps written any other way, like flow style
+// lists or aliases, are found to not match the loaded steps and have no position
+func mapStepSources(defBytes []byte) map[string]*stageSource {
+	sources := map[string]*stageSource{}
+	lines := sourceLines(defBytes)
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Indent != 0 || !lines[i].isKey("stages") {
+			continue
+		}
+
+		j := i + 1
+		if j >= len(lines) || lines[j].Indent == 0 {
+			break
+		}
+		stageIndent := lines[j].Indent
+		for j < len(lines) && lines[j].Indent >= stageIndent {
+			stageName, ok := lines[j].stageKey()
+			if !ok || lines[j].Indent != stageIndent {
+				j++
+				continue
+			}
+			j++
+			if j < len(lines) && !lines[j].isItem() && lines[j].Indent > stageIndent {
+				sources[stageName], j = mapDescribedStage(lines, j)
+				continue
+			}
+			source := &stageSource{}
+			source.Steps, j = mapStepItems(lines, j, stageIndent)
+			sources[stageName] = source
+		}
+		break
+	}
+	return sources
+}
+
+// mapDescribedStage - Maps the steps and hooks of a stage written as a map.
+// Returns the index after the stage
+func mapDescribedStage(lines []sourceLine, start int) (*stageSource, int) {
+	source := &stageSource{}
+	keyIndent := lines[start].Indent
+	i := start
+	for i < len(lines) && lines[i].Indent >= keyIndent {
+		if lines[i].Indent != keyIndent {
+			i++
+			continue
+		}
+		switch {
+		case lines[i].isKey("steps"):
+			source.Steps, i = mapStepItems(lines, i+1, keyIndent)
+		case lines[i].isKey("on_failure"):
+			source.OnFailure, i = mapStepItems(lines, i+1, keyIndent)
+		case lines[i].isKey("finally"):
+			source.Finally, i = mapStepItems(lines, i+1, keyIndent)
+		default:
+			i++
+		}
+	}
+	return source, i
+}
+
+// mapStepItems - Maps a list of steps starting at lines[start]. Items may be at
+// the same indent as their parent key. Returns the index after the list
+func mapStepItems(lines []sourceLine, start int, parentIndent int) ([]*stepSource, int) {
+	if start >= len(lines) || !lines[start].isItem() || lines[start].Indent < parentIndent {
+		return nil, start
+	}
+
+	var items []*stepSource
+	itemIndent := lines[start].Indent
+	i := start
+	for i < len(lines) && lines[i].Indent >= itemIndent {
+		if lines[i].Indent == itemIndent && !lines[i].isItem() {
+			break
+		}
+		if lines[i].Indent > itemIndent {
+			i++
+			continue
+		}
+
+		item := &stepSource{
+			Position: SourcePosition{Line: lines[i].Number, Column: lines[i].Indent + 1},
+		}
+		items = append(items, item)
+
+		// Keys of the item are indented to the text after the dash
+		keyText := strings.TrimLeft(lines[i].Text[1:], " ")
+		keyIndent := itemIndent + len(lines[i].Text) - len(keyText)
+		item.setKey(keyText)
+		i++
+		for i < len(lines) && lines[i].Indent > itemIndent {
+			if lines[i].Indent != keyIndent {
+				i++
+				continue
+			}
+			if lines[i].isKey("steps") {
+				item.Steps, i = mapStepItems(lines, i+1, keyIndent)
+				continue
+			}
+			item.setKey(lines[i].Text)
+			i++
+		}
+	}
+	return items, i
+}
+
+// setKey - Records the step's name or type from one of its key lines
+func (s *stepSource) setKey(text string) {
+	key, value, ok := keyValue(text)
+	switch {
+	case !ok:
+	case key == "name":
+		s.Name = value
+	case key == "type":
+		s.Type = value
+	}
+}
+
+// setStagePositions - Records where the steps and hooks of a stage were defined.
+// If any mapped step doesn't match the loaded step the mapping can't be trusted
+// and none of the stage's steps get a position
+func setStagePositions(stage Stage, source *stageSource) {
+	if source == nil {
+		return
+	}
+	if !sourcesMatch(stage.Steps, source.Steps) ||
+		!sourcesMatch(stage.OnFailure, source.OnFailure) ||
+		!sourcesMatch(stage.Finally, source.Finally) {
+		return
+	}
+	setPositions(stage.Steps, source.Steps)
+	setPositions(stage.OnFailure, source.OnFailure)
+	setPositions(stage.Finally, source.Finally)
+}
+
+// sourcesMatch - Checks that the mapped sources are the loaded steps
+func sourcesMatch(steps []*Step, sources []*stepSource) bool {
+	if len(steps) != len(sources) {
+		return false
+	}
+	for i, step := range steps {
+		if step.Name != sources[i].Name || step.Type != sources[i].Type {
+			return false
+		}
+		if !sourcesMatch(step.Steps, sources[i].Steps) {
+			return false
+		}
+	}
+	return true
+}
+
+// setPositions - Records where each step was defined using the mapped sources
+func setPositions(steps []*Step, sources []*stepSource) {
+	for i, step := range steps {
+		position := sources[i].Position
+		step.position = &position
+		setPositions(step.Steps, sources[i].Steps)
+	}
+}
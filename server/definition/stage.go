@@ -1,4 +1,31 @@
 package definition
 
-// Stage - Defines a stage
-type Stage []*Step
+// Stage - Defines a stage. A stage is written as a list of steps or, to describe
+// it or add hooks, as a map with its steps. OnFailure steps run after a step of the
+// stage fails and Finally steps always run last
+type Stage struct {
+	Description string  `yaml:"description,omitempty"`
+	Steps       []*Step `yaml:"steps"`
+	OnFailure   []*Step `yaml:"on_failure,omitempty"`
+	Finally     []*Step `yaml:"finally,omitempty"`
+}
+
+// UnmarshalYAML - Loads a stage from either of its forms
+func (s *Stage) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var steps []*Step
+	err := unmarshal(&steps)
+	if err == nil {
+		s.Steps = steps
+		return nil
+	}
+
+	// Use a separate type so this method isn't called again
+	type describedStage Stage
+	var stage describedStage
+	err = unmarshal(&stage)
+	if err != nil {
+		return err
+	}
+	*s = Stage(stage)
+	return nil
+}
@@ -1,6 +1,10 @@
 package definition
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Export - used for export variable definition
 type Export struct {
@@ -15,6 +19,10 @@ type StepArgs struct {
 	Stage   string            `yaml:"stage,omitempty"`
 	Params  map[string]string `yaml:"params,omitempty"`
 	Export  Export            `yaml:"export,omitempty"`
+
+	// Used by script steps. Shell is the interpreter and defaults to /bin/sh
+	Script string `yaml:"script,omitempty"`
+	Shell  string `yaml:"shell,omitempty"`
 }
 
 // Step - Defines a step
@@ -25,44 +33,225 @@ type Step struct {
 	MatchTags []string `yaml:"match_tags,omitempty"`
 	SkipTags  []string `yaml:"skip_tags,omitempty"`
 	Outputs   []string `yaml:"outputs,omitempty"`
+
+	// When is a template. The step is skipped unless it renders to a true value
+	When string `yaml:"when,omitempty"`
+
+	// Timeout is a duration such as "10m". A step running longer is killed
+	Timeout string       `yaml:"timeout,omitempty"`
+	Retry   *RetryPolicy `yaml:"retry,omitempty"`
+
+	// Used by stage steps. The stage runs once for each combination of the values
+	Matrix map[string][]string `yaml:"matrix,omitempty"`
+
+	// The matrix combination a step was copied for, if any
+	Combination *MatrixCombination `yaml:"-"`
+
+	// Used by parallel steps. Concurrency limits how many steps run at once
+	Steps       []*Step `yaml:"steps,omitempty"`
+	Concurrency int     `yaml:"concurrency,omitempty"`
+
+	// The resolved steps of a parallel step. Each branch runs in order
+	Branches [][]*Step `yaml:"-"`
+
+	// Where the step is defined in the definition file if it is known
+	position *SourcePosition
+}
+
+// Position - Gets where the step is defined in the definition file. This is nil
+// for steps that were not loaded from a file or that could not be located
+func (s *Step) Position() *SourcePosition {
+	return s.position
+}
+
+// RetryPolicy - Defines how a failed step is retried. Attempts includes the first
+// run. If OnExitCodes is empty any timeout or non-zero exit is retried
+type RetryPolicy struct {
+	Attempts    int    `yaml:"attempts"`
+	Backoff     string `yaml:"backoff,omitempty"`
+	OnExitCodes []int  `yaml:"on_exit_codes,omitempty"`
+}
+
+// MaxAttempts - The number of times a step may run
+func (r *RetryPolicy) MaxAttempts() int {
+	if r == nil || r.Attempts < 1 {
+		return 1
+	}
+	return r.Attempts
+}
+
+// BackoffDuration - The time to wait between attempts
+func (r *RetryPolicy) BackoffDuration() (time.Duration, error) {
+	if r == nil || r.Backoff == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(r.Backoff)
+}
+
+// RetriesExitCode - Checks if a step that exited with the given code should be retried
+func (r *RetryPolicy) RetriesExitCode(code int) bool {
+	if r == nil {
+		return false
+	}
+	if len(r.OnExitCodes) == 0 {
+		return true
+	}
+	for _, retryCode := range r.OnExitCodes {
+		if retryCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RetriesTimeout - Checks if a step that timed out should be retried
+func (r *RetryPolicy) RetriesTimeout() bool {
+	return r != nil && len(r.OnExitCodes) == 0
+}
+
+// TimeoutDuration - Parses the step's timeout. A zero duration means no timeout
+func (s *Step) TimeoutDuration() (time.Duration, error) {
+	if s.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s.Timeout)
+}
+
+// validateExecutionPolicy - Checks the step's timeout and retry policy
+func (s *Step) validateExecutionPolicy() error {
+	timeout, err := s.TimeoutDuration()
+	if err != nil || timeout < 0 {
+		return stepError(s, `Invalid Definition: step "%s" has an invalid timeout "%s"`, s.ReferenceName(), s.Timeout)
+	}
+
+	if s.Type == "parallel" && (s.Timeout != "" || s.Retry != nil) {
+		return stepError(s, `Invalid Definition: parallel step "%s" cannot have a timeout or retry. Set them on the steps it runs`, s.ReferenceName())
+	}
+
+	if s.Retry == nil {
+		return nil
+	}
+	if s.Retry.Attempts < 1 {
+		return stepError(s, `Invalid Definition: retry attempts for step "%s" must be at least 1`, s.ReferenceName())
+	}
+	backoff, err := s.Retry.BackoffDuration()
+	if err != nil || backoff < 0 {
+		return stepError(s, `Invalid Definition: step "%s" has an invalid retry backoff "%s"`, s.ReferenceName(), s.Retry.Backoff)
+	}
+	return nil
+}
+
+// validateArgs - Checks that the step has the arguments its type needs
+func (s *Step) validateArgs() error {
+	if s.Type == "script" && strings.TrimSpace(s.Args.Script) == "" {
+		return stepError(s, `Invalid Definition: script step "%s" requires a 'script' argument`, s.ReferenceName())
+	}
+	return nil
+}
+
+// IsTruthy - Checks if a rendered when condition is true. Empty values, "false",
+// "no", "off" and "0" are false, ignoring case and surrounding whitespace
+func IsTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "no", "off", "0":
+		return false
+	}
+	return true
+}
+
+// ShouldRun - Renders the step's when condition to decide if the step runs
+func (s *Step) ShouldRun(renderer *CorkTemplateRenderer) (bool, error) {
+	if s.When == "" {
+		return true, nil
+	}
+	condition, err := renderer.RenderInMatrix(s.When, s.Combination)
+	if err != nil {
+		return false, err
+	}
+	return IsTruthy(condition), nil
 }
 
 func (s *Step) ReferenceName() string {
 	if s.Name != "" {
 		return s.Name
 	}
-	return fmt.Sprintf("type:%s", s.Type)
+	return fmt.Sprintf("type:%s%s", s.Type, s.Combination.Suffix())
+}
+
+// MatchesTags - Checks the step's match_tags and skip_tags against the active tags.
+// A step with match_tags is only included if one of them is active and a step is
+// always dropped if any of its skip_tags are active
+func (s *Step) MatchesTags(tags []string) bool {
+	activeTags := map[string]bool{}
+	for _, tag := range tags {
+		activeTags[tag] = true
+	}
+
+	for _, skipTag := range s.SkipTags {
+		if activeTags[skipTag] {
+			return false
+		}
+	}
+
+	if len(s.MatchTags) == 0 {
+		return true
+	}
+	for _, matchTag := range s.MatchTags {
+		if activeTags[matchTag] {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveArgs - Renders the step's arguments with its matrix values
+func (s *Step) ResolveArgs(renderer *CorkTemplateRenderer) (*StepArgs, error) {
+	return s.Args.resolveArgs(renderer, s.Combination)
 }
 
 func (sa *StepArgs) ResolveArgs(renderer *CorkTemplateRenderer) (*StepArgs, error) {
-	image, err := renderer.Render(sa.Image)
+	return sa.resolveArgs(renderer, nil)
+}
+
+func (sa *StepArgs) resolveArgs(renderer *CorkTemplateRenderer, combination *MatrixCombination) (*StepArgs, error) {
+	image, err := renderer.RenderInMatrix(sa.Image, combination)
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := renderer.RenderInMatrix(sa.Command, combination)
+	if err != nil {
+		return nil, err
+	}
+
+	stage, err := renderer.RenderInMatrix(sa.Stage, combination)
 	if err != nil {
 		return nil, err
 	}
 
-	command, err := renderer.Render(sa.Command)
+	script, err := renderer.RenderInMatrix(sa.Script, combination)
 	if err != nil {
 		return nil, err
 	}
 
-	stage, err := renderer.Render(sa.Stage)
+	shell, err := renderer.RenderInMatrix(sa.Shell, combination)
 	if err != nil {
 		return nil, err
 	}
 
-	exportName, err := renderer.Render(sa.Export.Name)
+	exportName, err := renderer.RenderInMatrix(sa.Export.Name, combination)
 	if err != nil {
 		return nil, err
 	}
 
-	exportValue, err := renderer.Render(sa.Export.Value)
+	exportValue, err := renderer.RenderInMatrix(sa.Export.Value, combination)
 	if err != nil {
 		return nil, err
 	}
 
 	params := make(map[string]string)
 	for key, value := range sa.Params {
-		resolvedValue, err := renderer.Render(value)
+		resolvedValue, err := renderer.RenderInMatrix(value, combination)
 		if err != nil {
 			return nil, err
 		}
@@ -78,6 +267,8 @@ func (sa *StepArgs) ResolveArgs(renderer *CorkTemplateRenderer) (*StepArgs, erro
 			Value: exportValue,
 		},
 		Params: params,
+		Script: script,
+		Shell:  shell,
 	}
 	return &resolvedArgs, nil
 }
@@ -3,19 +3,21 @@ package definition
 import (
 	"bytes"
 	"fmt"
-	"html/template"
 	"os"
 	"strings"
+	"sync"
+	"text/template"
 )
 
 type Empty struct {
 }
 
 type CorkTemplateRendererOptions struct {
-	WorkDir     string
-	HostWorkDir string
-	CacheDir    string
-	UserParams  map[string]string
+	WorkDir      string
+	HostWorkDir  string
+	CacheDir     string
+	VolumePrefix string
+	UserParams   map[string]string
 }
 
 type TemplateVar struct {
@@ -31,13 +33,25 @@ type CorkTemplateRenderer struct {
 	WorkDir      string
 	HostWorkDir  string
 	CacheDir     string
+	VolumePrefix string
+
+	// Set once a step fails for on_failure and finally steps
+	FailedStep      string
+	FailedStepError string
+
+	// The matrix combination of the step being rendered
+	matrix *MatrixCombination
+
+	// Steps may render and add outputs concurrently
+	lock sync.Mutex
 }
 
 func NewTemplateRenderer() *CorkTemplateRenderer {
 	rendererOptions := CorkTemplateRendererOptions{
-		WorkDir:     os.Getenv("CORK_WORK_DIR"),
-		HostWorkDir: os.Getenv("CORK_HOST_WORK_DIR"),
-		CacheDir:    os.Getenv("CORK_CACHE_DIR"),
+		WorkDir:      os.Getenv("CORK_WORK_DIR"),
+		HostWorkDir:  os.Getenv("CORK_HOST_WORK_DIR"),
+		CacheDir:     os.Getenv("CORK_CACHE_DIR"),
+		VolumePrefix: os.Getenv("CORK_VOLUME_PREFIX"),
 	}
 	return NewTemplateRendererWithOptions(rendererOptions)
 }
@@ -50,6 +64,7 @@ func NewTemplateRendererWithOptions(options CorkTemplateRendererOptions) *CorkTe
 		WorkDir:      options.WorkDir,
 		HostWorkDir:  options.HostWorkDir,
 		CacheDir:     options.CacheDir,
+		VolumePrefix: options.VolumePrefix,
 		Outputs:      map[string]map[string]string{},
 		UserParams:   options.UserParams,
 		RequiredVars: map[string]TemplateVar{},
@@ -60,6 +75,11 @@ func NewTemplateRendererWithOptions(options CorkTemplateRendererOptions) *CorkTe
 		"HOST_WORK_DIR": renderer.hostWorkDir,
 		"CACHE_DIR":     renderer.cacheDir,
 		"param":         renderer.userResolve,
+		"volumes":       renderer.volumesResolve,
+		"matrix":        renderer.matrixResolve,
+
+		"FAILED_STEP":       renderer.failedStep,
+		"FAILED_STEP_ERROR": renderer.failedStepError,
 	}
 	renderer.FuncMap = funcMap
 	return renderer
@@ -71,13 +91,21 @@ func (c *CorkTemplateRenderer) trackRequiredVar(requiredVar TemplateVar) {
 }
 
 func (c *CorkTemplateRenderer) outputsResolve(lookup string) string {
+	// Step names in a matrix may have dots in their values
+	index := strings.LastIndex(lookup, ".")
+	if index < 0 {
+		c.trackRequiredVar(TemplateVar{
+			Type:   "output",
+			Lookup: lookup,
+		})
+		return ""
+	}
+	stepName := c.matrix.namespace(lookup[:index])
+	varName := lookup[index+1:]
 	c.trackRequiredVar(TemplateVar{
 		Type:   "output",
-		Lookup: lookup,
+		Lookup: fmt.Sprintf("%s.%s", stepName, varName),
 	})
-	splitLookup := strings.Split(lookup, ".")
-	stepName := splitLookup[0]
-	varName := splitLookup[1]
 	stepOutputs, ok := c.Outputs[stepName]
 	if !ok {
 		return ""
@@ -101,6 +129,30 @@ func (c *CorkTemplateRenderer) cacheDir() string {
 	return c.CacheDir
 }
 
+func (c *CorkTemplateRenderer) failedStep() string {
+	c.trackRequiredVar(TemplateVar{
+		Type:   "failure",
+		Lookup: "FAILED_STEP",
+	})
+	return c.FailedStep
+}
+
+func (c *CorkTemplateRenderer) failedStepError() string {
+	c.trackRequiredVar(TemplateVar{
+		Type:   "failure",
+		Lookup: "FAILED_STEP_ERROR",
+	})
+	return c.FailedStepError
+}
+
+// SetFailure - Records the step that failed and its error for on_failure and finally steps
+func (c *CorkTemplateRenderer) SetFailure(stepName string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.FailedStep = stepName
+	c.FailedStepError = err.Error()
+}
+
 func (c *CorkTemplateRenderer) userResolve(lookup string) string {
 	c.trackRequiredVar(TemplateVar{
 		Type:   "user",
@@ -113,7 +165,31 @@ func (c *CorkTemplateRenderer) userResolve(lookup string) string {
 	return userParamValue
 }
 
+func (c *CorkTemplateRenderer) volumesResolve(name string) string {
+	c.trackRequiredVar(TemplateVar{
+		Type:   "volume",
+		Lookup: name,
+	})
+	return c.VolumeName(name)
+}
+
+func (c *CorkTemplateRenderer) matrixResolve(key string) string {
+	c.trackRequiredVar(TemplateVar{
+		Type:   "matrix",
+		Lookup: key,
+	})
+	value, _ := c.matrix.Value(key)
+	return value
+}
+
+// VolumeName - The project scoped name of a named volume
+func (c *CorkTemplateRenderer) VolumeName(name string) string {
+	return fmt.Sprintf("%s%s", c.VolumePrefix, name)
+}
+
 func (c *CorkTemplateRenderer) AddOutput(stepName string, varName string, value string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	stepOutputs, ok := c.Outputs[stepName]
 	if !ok {
 		stepOutputs = map[string]string{}
@@ -122,7 +198,42 @@ func (c *CorkTemplateRenderer) AddOutput(stepName string, varName string, value
 	stepOutputs[varName] = value
 }
 
+// StepOutputs - Gets a copy of the outputs a step added
+func (c *CorkTemplateRenderer) StepOutputs(stepName string) map[string]string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	outputs := map[string]string{}
+	for key, value := range c.Outputs[stepName] {
+		outputs[key] = value
+	}
+	return outputs
+}
+
+// ListOutputs - Gets a copy of the outputs of every step
+func (c *CorkTemplateRenderer) ListOutputs() map[string]map[string]string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	outputs := map[string]map[string]string{}
+	for stepName, stepOutputs := range c.Outputs {
+		outputs[stepName] = map[string]string{}
+		for key, value := range stepOutputs {
+			outputs[stepName][key] = value
+		}
+	}
+	return outputs
+}
+
 func (c *CorkTemplateRenderer) Render(templateStr string) (string, error) {
+	return c.RenderInMatrix(templateStr, nil)
+}
+
+// RenderInMatrix - Renders a template for a step of a matrix combination. Output
+// lookups of steps in the combination use their namespaced names
+func (c *CorkTemplateRenderer) RenderInMatrix(templateStr string, combination *MatrixCombination) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.matrix = combination
+	defer func() { c.matrix = nil }()
 	tmpl, err := template.New("line").Funcs(c.FuncMap).Parse(templateStr)
 	if err != nil {
 		return "", err
@@ -136,9 +247,19 @@ func (c *CorkTemplateRenderer) Render(templateStr string) (string, error) {
 }
 
 func (c *CorkTemplateRenderer) ResetRequiredVarTracker() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	c.RequiredVars = map[string]TemplateVar{}
 }
 
+// ListRequiredVars - Copies the variables used by templates rendered since the
+// tracker was reset
 func (c *CorkTemplateRenderer) ListRequiredVars() map[string]TemplateVar {
-	return c.RequiredVars
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	requiredVars := make(map[string]TemplateVar, len(c.RequiredVars))
+	for key, requiredVar := range c.RequiredVars {
+		requiredVars[key] = requiredVar
+	}
+	return requiredVars
 }
@@ -51,3 +51,14 @@ func TestTemplateRenderUserParams(t *testing.T) {
 		assert.Equal(t, "bar", rendered2)
 	}
 }
+
+func TestTemplateRenderVolumes(t *testing.T) {
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir:      "/work",
+		VolumePrefix: "cork-project-",
+	})
+	rendered, err := renderer.Render(`{{ volumes "node_modules_cache" }}:{{ WORK_DIR }}/node_modules`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "cork-project-node_modules_cache:/work/node_modules", rendered)
+	}
+}
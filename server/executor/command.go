@@ -37,7 +37,7 @@ func CommandStepRunnerFactory(params StepRunnerParams) (StepRunner, error) {
 // CommandStepHandler - Handles executing a command step
 func CommandStepHandler(corkDir string, executor *StepsExecutor, stream streamer.StepStream, step *definition.Step) (map[string]string, error) {
 	log.Debugf("Running command step %s", step.Name)
-	args, err := step.Args.ResolveArgs(executor.Renderer)
+	args, err := step.ResolveArgs(executor.Renderer)
 	if err != nil {
 		log.Debugf("Error resolving arguments: %v", err)
 		return nil, err
@@ -72,6 +72,7 @@ func CommandStepHandler(corkDir string, executor *StepsExecutor, stream streamer
 	cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_WORK_DIR=%s", executor.Renderer.WorkDir))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_HOST_WORK_DIR=%s", executor.Renderer.HostWorkDir))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("CACHE_DIR=%s", executor.Renderer.CacheDir))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_CACHE_DIR=%s", executor.Renderer.CacheDir))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_OUTPUTS_DIR=%s", outputsDir))
 
 	log.Debugf("Env for %s: %v", step.Name, cmd.Env)
@@ -115,6 +116,19 @@ func getOutputs(commandName string, outputsDir string, outputKeys []string) (map
 	return outputs, nil
 }
 
+// paramsEnv - Converts a step's params to environment variables
+func paramsEnv(params map[string]string) []string {
+	var env []string
+	for key, value := range params {
+		upperKey := strings.ToUpper(key)
+		env = append(env, fmt.Sprintf("CORK_PARAM_%s=%s", upperKey, value))
+
+		// Support the old style until we get all existing cork servers using this
+		env = append(env, fmt.Sprintf("%s=%s", upperKey, value))
+	}
+	return env
+}
+
 type StdinPiper struct {
 	InputBytesChan chan []byte
 	KillInput      chan bool
@@ -143,8 +157,17 @@ func (s *StdinPiper) Kill() {
 	s.KillInput <- true
 }
 
+// Close - Ends any pending or future reads on the piper
+func (s *StdinPiper) Close() error {
+	close(s.KillInput)
+	return nil
+}
+
 func (s *StdinPiper) Write(bytes []byte) {
-	s.InputBytesChan <- bytes
+	select {
+	case s.InputBytesChan <- bytes:
+	case <-s.KillInput:
+	}
 }
 
 type Command struct {
@@ -274,15 +297,17 @@ func (s *Command) ExecCommand() *exec.Cmd {
 	return cmd
 }
 
+// CommandStepRunner - Runs a command on a pty. The streamer is created before the
+// command runs so input, signals and resizes that arrive before it starts are
+// applied once it has started
 type CommandStepRunner struct {
 	Params       StepRunnerParams
 	Cmd          *exec.Cmd
-	StdinPiper   *StdinPiper
 	StepStreamer *streamer.StepStreamer
 }
 
 func (c *CommandStepRunner) Initialize(params StepRunnerParams) error {
-	c.Params = params
+	c.setParams(params)
 	log.Debugf("Loading command: %s", c.Params.Args.Command)
 
 	command, err := LoadCommand(c.Params.Context.CorkDir, c.Params.Args.Command)
@@ -294,22 +319,24 @@ func (c *CommandStepRunner) Initialize(params StepRunnerParams) error {
 	return nil
 }
 
+// setParams - Sets the params and creates the streamer so the step can be
+// resized before it runs
+func (c *CommandStepRunner) setParams(params StepRunnerParams) {
+	c.Params = params
+	c.StepStreamer = streamer.NewWithSecrets(params.Stream, params.Context.Secrets)
+	c.StepStreamer.Resize(params.Context.TerminalSize)
+}
+
 func (c *CommandStepRunner) Run() {
 	context := c.Params.Context
 	log.Debugf("Executing command: %s", c.Params.Args.Command)
 
-	stepStreamer := streamer.New(c.Params.Stream)
+	stepStreamer := c.StepStreamer
 	defer stepStreamer.Close()
 
 	cmd := c.Cmd
 
-	for key, value := range c.Params.Args.Params {
-		upperKey := strings.ToUpper(key)
-		cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_PARAM_%s=%s", upperKey, value))
-
-		// Support the old style until we get all existing cork servers using this
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", upperKey, value))
-	}
+	cmd.Env = append(cmd.Env, paramsEnv(c.Params.Args.Params)...)
 
 	cmd.Dir = context.WorkDir
 
@@ -317,19 +344,15 @@ func (c *CommandStepRunner) Run() {
 	cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_WORK_DIR=%s", context.WorkDir))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_HOST_WORK_DIR=%s", context.HostWorkDir))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("CACHE_DIR=%s", context.CacheDir))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_CACHE_DIR=%s", context.CacheDir))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("CORK_OUTPUTS_DIR=%s", context.OutputsDir))
 
 	log.Debugf("Env for command %s: %v", c.Params.Args.Command, cmd.Env)
 
-	stdinPiper := NewStdinPiper()
-
-	c.StdinPiper = stdinPiper
-	c.StepStreamer = stepStreamer
-	cmd.Stdin = stdinPiper
-
+	// The pty is the command's stdin
 	err := stepStreamer.Run(cmd)
 	if err != nil {
-		log.Debugf("Command %s encountered an error")
+		log.Debugf("Command %s encountered an error", c.Params.Args.Command)
 		c.Params.ErrorChan <- err
 		c.Params.DoneChan <- true
 		return
@@ -353,6 +376,19 @@ func (c *CommandStepRunner) HandleInput(bytes []byte) error {
 	return nil
 }
 
+// HandleSignal - Sends the signal to the command's process group so anything the
+// command spawned gets it too
 func (c *CommandStepRunner) HandleSignal(signal int32) error {
-	return nil
+	return c.StepStreamer.Signal(syscall.Signal(signal))
+}
+
+// HandleResize - Resizes the command's pty
+func (c *CommandStepRunner) HandleResize(size streamer.TerminalSize) error {
+	return c.StepStreamer.Resize(size)
+}
+
+// Kill - Kills the command's process group. Commands are started in their own
+// session so this also stops anything the command spawned
+func (c *CommandStepRunner) Kill() error {
+	return c.StepStreamer.Signal(syscall.SIGKILL)
 }
@@ -1,22 +1,31 @@
 package executor
 
 import (
+	"archive/tar"
+	"bytes"
 	"fmt"
-	"os"
+	"io"
+	"io/ioutil"
+	"path"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
-	log "github.com/sirupsen/logrus"
 	docker "github.com/fsouza/go-dockerclient"
+	"github.com/kballard/go-shellquote"
 	"github.com/renstrom/fuzzysearch/fuzzy"
-	"github.com/virtru/cork/server/definition"
+	log "github.com/sirupsen/logrus"
 	"github.com/virtru/cork/server/streamer"
 )
 
 var authHTTPRegex = regexp.MustCompile("^https?://")
 
+// The path where a container step is expected to write its outputs
+const containerOutputsDir = "/cork-outputs"
+
 func init() {
-	RegisterHandler("container", ContainerStepHandler)
+	RegisterRunner("container", ContainerStepRunnerFactory)
 }
 
 func narrowAuthSearch(repo string, auths *docker.AuthConfigurations) []docker.AuthConfiguration {
@@ -51,16 +60,19 @@ func narrowAuthSearch(repo string, auths *docker.AuthConfigurations) []docker.Au
 	return authConfigs
 }
 
-func tryPullImage(client *docker.Client, image string) error {
+// tryPullImage - Pulls the image, trying each matching auth config. Progress is
+// written to output
+func tryPullImage(client *docker.Client, image string, output io.Writer) error {
+	repo, tag := docker.ParseRepositoryTag(image)
+
+	var authConfigs []docker.AuthConfiguration
 	auths, err := docker.NewAuthConfigurationsFromDockerCfg()
 	if err != nil {
-		return err
+		log.Debugf("Cannot load docker config (this is not necessarily bad): %v", err)
+	} else {
+		authConfigs = narrowAuthSearch(repo, auths)
 	}
 
-	repo, tag := docker.ParseRepositoryTag(image)
-
-	authConfigs := narrowAuthSearch(repo, auths)
-
 	if len(authConfigs) == 0 {
 		authConfigs = []docker.AuthConfiguration{
 			docker.AuthConfiguration{},
@@ -70,7 +82,7 @@ func tryPullImage(client *docker.Client, image string) error {
 	pullImageOptions := docker.PullImageOptions{
 		Repository:   repo,
 		Tag:          tag,
-		OutputStream: os.Stdout,
+		OutputStream: terminalLineWriter{output},
 	}
 
 	for _, authConfig := range authConfigs {
@@ -84,8 +96,378 @@ func tryPullImage(client *docker.Client, image string) error {
 	return fmt.Errorf("PullImage failed.")
 }
 
-// ContainerStepHandler - Handles executing a command step
-func ContainerStepHandler(corkDir string, executor *StepsExecutor, stream streamer.StepStream, step *definition.Step) (map[string]string, error) {
-	log.Debugf("Running container step %s", step.Name)
-	return nil, nil
+func ContainerStepRunnerFactory(params StepRunnerParams) (StepRunner, error) {
+	runner := &ContainerStepRunner{}
+
+	err := runner.Initialize(params)
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
+
+type ContainerStepRunner struct {
+	Params       StepRunnerParams
+	Client       *docker.Client
+	Container    *docker.Container
+	StdinPiper   *StdinPiper
+	StepStreamer *streamer.StepStreamer
+
+	// The container and its stdin are set while the step runs. Input that arrives
+	// before the container is attached is written once it is, and input that
+	// arrives after the step finished is dropped
+	lock         sync.Mutex
+	pendingInput [][]byte
+	killed       bool
+	finished     bool
+}
+
+func (c *ContainerStepRunner) Initialize(params StepRunnerParams) error {
+	c.Params = params
+	if c.Params.Args.Image == "" {
+		return fmt.Errorf("'container' step requires an 'image' argument")
+	}
+
+	client, err := docker.NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+	c.Client = client
+	return nil
+}
+
+func (c *ContainerStepRunner) Run() {
+	err := c.run()
+	if err != nil {
+		log.Debugf("Container %s encountered an error: %v", c.Params.Args.Image, err)
+		c.Params.ErrorChan <- err
+		c.Params.DoneChan <- true
+		return
+	}
+	c.Params.DoneChan <- true
+	return
+}
+
+func (c *ContainerStepRunner) run() error {
+	defer c.finish()
+	image := c.Params.Args.Image
+
+	stepStreamer := streamer.NewWithSecrets(c.Params.Stream, c.Params.Context.Secrets)
+	defer stepStreamer.Close()
+	c.StepStreamer = stepStreamer
+	outputWriter := stepStreamer.OutputWriter()
+
+	err := c.ensureImage(outputWriter)
+	if err != nil {
+		return err
+	}
+
+	createOptions, err := c.createContainerOptions()
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Creating container for image %s", image)
+	container, err := c.Client.CreateContainer(createOptions)
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	c.Container = container
+	c.lock.Unlock()
+	defer c.removeContainer()
+	if c.wasKilled() {
+		return fmt.Errorf("Container step was killed before it started")
+	}
+
+	err = c.Client.UploadToContainer(container.ID, docker.UploadToContainerOptions{
+		InputStream: outputsDirArchive(),
+		Path:        "/",
+	})
+	if err != nil {
+		return err
+	}
+
+	stdinPiper := NewStdinPiper()
+	defer stdinPiper.Close()
+
+	closeWaiter, err := c.Client.AttachToContainerNonBlocking(docker.AttachToContainerOptions{
+		Container:    container.ID,
+		InputStream:  stdinPiper,
+		OutputStream: outputWriter,
+		ErrorStream:  outputWriter,
+		RawTerminal:  true,
+		Stream:       true,
+		Stdin:        true,
+		Stdout:       true,
+		Stderr:       true,
+	})
+	if err != nil {
+		return err
+	}
+	defer closeWaiter.Close()
+
+	c.attachStdin(stdinPiper)
+
+	log.Debugf("Starting container %s", container.ID)
+	err = c.Client.StartContainer(container.ID, nil)
+	if err != nil {
+		return err
+	}
+	// A kill while the container was starting may have found nothing running.
+	// Removing the container stops it
+	if c.wasKilled() {
+		return fmt.Errorf("Container step was killed before it started")
+	}
+
+	err = c.HandleResize(c.Params.Context.TerminalSize)
+	if err != nil {
+		log.Debugf("Error resizing container %s: %v", container.ID, err)
+	}
+
+	exitCode, err := c.Client.WaitContainer(container.ID)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the remaining output to be streamed
+	err = closeWaiter.Wait()
+	if err != nil {
+		log.Debugf("Error streaming output of container %s: %v", container.ID, err)
+	}
+	err = outputWriter.Flush()
+	if err != nil {
+		return err
+	}
+
+	if exitCode != 0 {
+		return StepExitError{
+			Code:    exitCode,
+			Message: fmt.Sprintf("Container %s exited with status %d", image, exitCode),
+		}
+	}
+
+	return c.copyOutputs()
+}
+
+func (c *ContainerStepRunner) ensureImage(output io.Writer) error {
+	image := c.Params.Args.Image
+	_, err := c.Client.InspectImage(image)
+	if err == nil {
+		return nil
+	}
+	if err != docker.ErrNoSuchImage {
+		return err
+	}
+	log.Debugf("Pulling image %s", image)
+	return tryPullImage(c.Client, image, output)
+}
+
+func (c *ContainerStepRunner) createContainerOptions() (docker.CreateContainerOptions, error) {
+	context := c.Params.Context
+
+	env := paramsEnv(c.Params.Args.Params)
+	env = append(env, fmt.Sprintf("CORK_DIR=%s", context.CorkDir))
+	env = append(env, fmt.Sprintf("CORK_WORK_DIR=%s", context.WorkDir))
+	env = append(env, fmt.Sprintf("CORK_HOST_WORK_DIR=%s", context.HostWorkDir))
+	env = append(env, fmt.Sprintf("CACHE_DIR=%s", context.CacheDir))
+	env = append(env, fmt.Sprintf("CORK_CACHE_DIR=%s", context.CacheDir))
+	env = append(env, fmt.Sprintf("CORK_OUTPUTS_DIR=%s", containerOutputsDir))
+
+	config := &docker.Config{
+		Image:        c.Params.Args.Image,
+		Env:          env,
+		WorkingDir:   context.WorkDir,
+		Tty:          true,
+		OpenStdin:    true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	if c.Params.Args.Command != "" {
+		cmdSplit, err := shellquote.Split(c.Params.Args.Command)
+		if err != nil {
+			return docker.CreateContainerOptions{}, err
+		}
+		config.Cmd = cmdSplit
+	}
+
+	// Containers are siblings of the cork server so the work dir must be
+	// bound from the host's perspective
+	binds := []string{
+		fmt.Sprintf("%s:%s", context.HostWorkDir, context.WorkDir),
+	}
+	binds = append(binds, context.Mounts...)
+
+	hostConfig := &docker.HostConfig{
+		Binds: binds,
+	}
+
+	return docker.CreateContainerOptions{
+		Config:     config,
+		HostConfig: hostConfig,
+	}, nil
+}
+
+// copyOutputs - Copies the outputs written by the container into the outputs dir
+func (c *ContainerStepRunner) copyOutputs() error {
+	var archive bytes.Buffer
+	err := c.Client.DownloadFromContainer(c.Container.ID, docker.DownloadFromContainerOptions{
+		Path:         containerOutputsDir,
+		OutputStream: &archive,
+	})
+	if err != nil {
+		return err
+	}
+
+	reader := tar.NewReader(&archive)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		outputPath := path.Join(c.Params.Context.OutputsDir, path.Base(header.Name))
+		err = ioutil.WriteFile(outputPath, data, 0600)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *ContainerStepRunner) removeContainer() {
+	err := c.Client.RemoveContainer(docker.RemoveContainerOptions{
+		ID:            c.Container.ID,
+		RemoveVolumes: true,
+		Force:         true,
+	})
+	if err != nil {
+		log.Debugf("Error removing container %s: %v", c.Container.ID, err)
+	}
+}
+
+// attachStdin - Writes the input that arrived before the container was attached,
+// in order, then sends later input straight to the container
+func (c *ContainerStepRunner) attachStdin(stdinPiper *StdinPiper) {
+	for {
+		c.lock.Lock()
+		pendingInput := c.pendingInput
+		c.pendingInput = nil
+		if len(pendingInput) == 0 {
+			c.StdinPiper = stdinPiper
+			c.lock.Unlock()
+			return
+		}
+		c.lock.Unlock()
+		for _, input := range pendingInput {
+			stdinPiper.Write(input)
+		}
+	}
+}
+
+// finish - Stops taking input once the step is done. The container no longer
+// reads its stdin so writing to it would block
+func (c *ContainerStepRunner) finish() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.finished = true
+	c.StdinPiper = nil
+	c.pendingInput = nil
+}
+
+func (c *ContainerStepRunner) HandleInput(bytes []byte) error {
+	c.lock.Lock()
+	if c.finished {
+		c.lock.Unlock()
+		return nil
+	}
+	stdinPiper := c.StdinPiper
+	if stdinPiper == nil {
+		c.pendingInput = append(c.pendingInput, append([]byte{}, bytes...))
+	}
+	c.lock.Unlock()
+	if stdinPiper != nil {
+		stdinPiper.Write(bytes)
+	}
+	return nil
+}
+
+func (c *ContainerStepRunner) wasKilled() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.killed
+}
+
+// runningContainer - The container of the step once it has been created
+func (c *ContainerStepRunner) runningContainer() *docker.Container {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.Container
+}
+
+func (c *ContainerStepRunner) HandleSignal(signal int32) error {
+	container := c.runningContainer()
+	if container == nil {
+		return nil
+	}
+	return c.Client.KillContainer(docker.KillContainerOptions{
+		ID:     container.ID,
+		Signal: docker.Signal(signal),
+	})
+}
+
+// HandleResize - Resizes the container's tty once it has started
+func (c *ContainerStepRunner) HandleResize(size streamer.TerminalSize) error {
+	container := c.runningContainer()
+	if container == nil || !size.IsKnown() {
+		return nil
+	}
+	return c.Client.ResizeContainerTTY(container.ID, int(size.Rows), int(size.Cols))
+}
+
+// Kill - Kills the container. A step that is killed before its container is
+// created stops without starting it
+func (c *ContainerStepRunner) Kill() error {
+	c.lock.Lock()
+	c.killed = true
+	c.lock.Unlock()
+	return c.HandleSignal(int32(docker.SIGKILL))
+}
+
+// terminalLineWriter - Ends lines with CRLF since the client's terminal is in raw
+// mode while steps run
+type terminalLineWriter struct {
+	writer io.Writer
+}
+
+func (t terminalLineWriter) Write(p []byte) (int, error) {
+	_, err := t.writer.Write(bytes.Replace(p, []byte("\n"), []byte("\r\n"), -1))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// outputsDirArchive - Creates a tar archive of an empty, world writable outputs dir
+func outputsDirArchive() io.Reader {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	writer.WriteHeader(&tar.Header{
+		Name:     strings.TrimPrefix(containerOutputsDir, "/") + "/",
+		Mode:     0777,
+		Typeflag: tar.TypeDir,
+		ModTime:  time.Now(),
+	})
+	writer.Close()
+	return &archive
 }
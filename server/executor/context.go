@@ -1,9 +1,18 @@
 package executor
 
+import "github.com/virtru/cork/server/streamer"
+
 type ExecContext struct {
 	CorkDir     string
 	WorkDir     string
 	HostWorkDir string
 	CacheDir    string
 	OutputsDir  string
+	Mounts      []string
+
+	// Values of sensitive params that must not appear in output or exports
+	Secrets []string
+
+	// The size of the client's terminal when the step started
+	TerminalSize streamer.TerminalSize
 }
@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/capture"
+	"github.com/virtru/cork/server/definition"
+)
+
+// The statuses of steps and stages in lifecycle events
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// StageStatus - The status of a stage that ended with the error
+func StageStatus(err error) string {
+	if err != nil {
+		return StatusFailed
+	}
+	return StatusSucceeded
+}
+
+// durationMs - The milliseconds since the start time
+func durationMs(started time.Time) int64 {
+	return int64(time.Since(started) / time.Millisecond)
+}
+
+// indexSteps - Numbers the steps in the order they are listed, including the
+// steps of parallel steps and hooks, starting at 1
+func (se *StepsExecutor) indexSteps() {
+	se.stepIndexes = map[*definition.Step]int32{}
+	var index func(steps []*definition.Step)
+	index = func(steps []*definition.Step) {
+		for _, step := range steps {
+			se.stepIndexes[step] = int32(len(se.stepIndexes) + 1)
+			for _, branch := range step.Branches {
+				index(branch)
+			}
+		}
+	}
+	index(se.Steps)
+	index(se.OnFailure)
+	index(se.Finally)
+}
+
+func (se *StepsExecutor) sendEvent(event *pb.ExecuteOutputEvent) {
+	err := se.Stream.Send(event)
+	if err != nil {
+		log.Debugf("Error sending %s event: %v", event.GetType(), err)
+	}
+}
+
+// sendStepStarted - Announces an attempt of a step. Returns when it started
+func (se *StepsExecutor) sendStepStarted(step *definition.Step, attempt int) time.Time {
+	se.sendEvent(&pb.ExecuteOutputEvent{
+		Type: "stepStarted",
+		Body: &pb.ExecuteOutputEvent_StepStarted{
+			StepStarted: &pb.StepStartedEvent{
+				Name:    step.ReferenceName(),
+				Type:    step.Type,
+				Index:   se.stepIndexes[step],
+				Attempt: int32(attempt),
+			},
+		},
+	})
+	return time.Now()
+}
+
+// sendStepFinished - Reports the result of an attempt of a step with its outputs
+func (se *StepsExecutor) sendStepFinished(step *definition.Step, attempt int, started time.Time, err error) {
+	finished := &pb.StepFinishedEvent{
+		Name:       step.ReferenceName(),
+		Type:       step.Type,
+		Index:      se.stepIndexes[step],
+		Attempt:    int32(attempt),
+		Status:     StatusSucceeded,
+		DurationMs: durationMs(started),
+	}
+
+	if err != nil {
+		finished.Status = StatusFailed
+		finished.Error = capture.RedactString(se.Secrets, err.Error())
+		finished.ExitCode = -1
+		if code, ok := exitCodeOf(err); ok {
+			finished.ExitCode = int32(code)
+		}
+	} else if step.Name != "" {
+		finished.Outputs = map[string]string{}
+		for key, value := range se.Renderer.StepOutputs(step.Name) {
+			finished.Outputs[key] = capture.RedactString(se.Secrets, value)
+		}
+	}
+
+	se.sendEvent(&pb.ExecuteOutputEvent{
+		Type: "stepFinished",
+		Body: &pb.ExecuteOutputEvent_StepFinished{
+			StepFinished: finished,
+		},
+	})
+}
+
+// sendStepSkipped - Reports a step that did not run because of its when condition
+func (se *StepsExecutor) sendStepSkipped(step *definition.Step) {
+	se.sendEvent(&pb.ExecuteOutputEvent{
+		Type: "stepFinished",
+		Body: &pb.ExecuteOutputEvent_StepFinished{
+			StepFinished: &pb.StepFinishedEvent{
+				Name:   step.ReferenceName(),
+				Type:   step.Type,
+				Index:  se.stepIndexes[step],
+				Status: StatusSkipped,
+			},
+		},
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/fatih/color"
 	log "github.com/sirupsen/logrus"
@@ -18,9 +20,20 @@ type StepsExecutor struct {
 	CorkDir        string
 	Stream         streamer.StepStream
 	Steps          []*definition.Step
+	Mounts         []string
+	Secrets        []string
+	OnFailure      []*definition.Step
+	Finally        []*definition.Step
 	InputChan      chan *pb.ExecuteInputEvent
 	InputErrorChan chan error
 	InputWait      chan bool
+
+	matrixResults *matrixResults
+	stepIndexes   map[*definition.Step]int32
+
+	// The latest size of the client's terminal. Steps start with it
+	sizeLock     sync.Mutex
+	terminalSize streamer.TerminalSize
 }
 
 func NewExecutor(corkDir string, renderer *definition.CorkTemplateRenderer, stream streamer.StepStream, steps []*definition.Step) *StepsExecutor {
@@ -30,7 +43,7 @@ func NewExecutor(corkDir string, renderer *definition.CorkTemplateRenderer, stre
 	return &StepsExecutor{
 		Renderer:       renderer,
 		CorkDir:        corkDir,
-		Stream:         stream,
+		Stream:         streamer.NewSyncStream(stream),
 		Steps:          steps,
 		InputChan:      inputChan,
 		InputErrorChan: inputErrorChan,
@@ -50,6 +63,10 @@ func (se *StepsExecutor) receiveInput() {
 				close(se.InputWait)
 				return
 			}
+			if input.GetType() == "resize" {
+				// Record the size right away so steps that start next get it
+				se.setTerminalSize(input.GetResize())
+			}
 			se.InputChan <- input
 		}
 	}()
@@ -59,7 +76,17 @@ func (se *StepsExecutor) handleInput(input *pb.ExecuteInputEvent, runner StepRun
 	inputType := input.GetType()
 	switch inputType {
 	case "signal":
-		log.Debugf("Received a signal")
+		signal := input.GetSignal().GetSignal()
+		log.Debugf("Sending signal %d to the running step", signal)
+		err := runner.HandleSignal(signal)
+		if err != nil {
+			log.Debugf("Error sending signal %d: %v", signal, err)
+		}
+	case "resize":
+		err := runner.HandleResize(se.getTerminalSize())
+		if err != nil {
+			log.Debugf("Error resizing the running step: %v", err)
+		}
 	case "input":
 		log.Debugf("Received input data")
 		runner.HandleInput(input.GetBody().(*pb.ExecuteInputEvent_Input).Input.Bytes)
@@ -67,25 +94,132 @@ func (se *StepsExecutor) handleInput(input *pb.ExecuteInputEvent, runner StepRun
 	return nil
 }
 
+// setTerminalSize - Records the size of the client's terminal from a resize event
+func (se *StepsExecutor) setTerminalSize(resize *pb.ResizeEvent) {
+	se.sizeLock.Lock()
+	defer se.sizeLock.Unlock()
+	se.terminalSize = streamer.TerminalSize{
+		Rows: uint16(resize.GetRows()),
+		Cols: uint16(resize.GetCols()),
+	}
+}
+
+func (se *StepsExecutor) getTerminalSize() streamer.TerminalSize {
+	se.sizeLock.Lock()
+	defer se.sizeLock.Unlock()
+	return se.terminalSize
+}
+
 func (se *StepsExecutor) sendOutput() error {
 	return nil
 }
 
+// StepFailure - A step of the stage failed
+type StepFailure struct {
+	Step string
+	Err  error
+}
+
+func (sf StepFailure) Error() string {
+	return sf.Err.Error()
+}
+
+// ExitCode - The exit code of the step if it failed by exiting
+func (sf StepFailure) ExitCode() (int, bool) {
+	return exitCodeOf(sf.Err)
+}
+
+// HookError - The errors of on_failure or finally steps that failed. The error
+// of the stage's steps, if any, is kept so it isn't hidden by the hooks
+type HookError struct {
+	Err        error
+	HookErrors []error
+}
+
+func (he HookError) Error() string {
+	var messages []string
+	if he.Err != nil {
+		messages = append(messages, he.Err.Error())
+	}
+	for _, hookErr := range he.HookErrors {
+		messages = append(messages, fmt.Sprintf("Hook failed: %v", hookErr))
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Execute - Runs the steps. If a step fails the on_failure steps run. The finally
+// steps always run last
 func (se *StepsExecutor) Execute() error {
 	se.receiveInput()
+	se.matrixResults = newMatrixResults(se.Steps, se.OnFailure, se.Finally)
+	se.indexSteps()
+	defer se.matrixResults.Print()
 
-	for _, step := range se.Steps {
+	failedStep, err := se.executeSteps(se.Steps)
+	if err != nil && failedStep != "" {
+		err = StepFailure{
+			Step: failedStep,
+			Err:  err,
+		}
+	}
+
+	var hookErrors []error
+	if err != nil && len(se.OnFailure) > 0 {
+		se.Renderer.SetFailure(failedStep, err)
+		color.Yellow("\n>>> Running on_failure steps\n")
+		hookErr := se.executeHooks(se.OnFailure)
+		if hookErr != nil {
+			hookErrors = append(hookErrors, hookErr)
+		}
+	}
+
+	if len(se.Finally) > 0 {
+		if err != nil {
+			se.Renderer.SetFailure(failedStep, err)
+		}
+		color.Yellow("\n>>> Running finally steps\n")
+		hookErr := se.executeHooks(se.Finally)
+		if hookErr != nil {
+			hookErrors = append(hookErrors, hookErr)
+		}
+	}
+
+	if len(hookErrors) > 0 {
+		return HookError{
+			Err:        err,
+			HookErrors: hookErrors,
+		}
+	}
+	return err
+}
+
+// executeHooks - Runs on_failure or finally steps
+func (se *StepsExecutor) executeHooks(hooks []*definition.Step) error {
+	failedStep, err := se.executeSteps(hooks)
+	if err != nil && failedStep != "" {
+		return StepFailure{
+			Step: failedStep,
+			Err:  err,
+		}
+	}
+	return err
+}
+
+// executeSteps - Runs steps in order until one fails. Returns the name of the
+// step that failed with its error
+func (se *StepsExecutor) executeSteps(steps []*definition.Step) (string, error) {
+	for _, step := range steps {
 		log.Debugf("Step: %+v", step)
-		err := se.ExecuteStep(step)
+		failedStep, err := se.executeStep(step)
 		if err != nil {
 			log.Debugf("Step Error: %+v", err)
-			return err
+			return failedStep, err
 		}
 	}
-	return nil
+	return "", nil
 }
 
-func (se *StepsExecutor) makeStepRunnerParams(doneChan chan bool, errorChan chan error, args *definition.StepArgs, outputsDir string) StepRunnerParams {
+func (se *StepsExecutor) makeStepRunnerParams(doneChan chan bool, errorChan chan error, args *definition.StepArgs, outputsDir string, stream streamer.StepStream) StepRunnerParams {
 	return StepRunnerParams{
 		DoneChan:  doneChan,
 		ErrorChan: errorChan,
@@ -96,40 +230,168 @@ func (se *StepsExecutor) makeStepRunnerParams(doneChan chan bool, errorChan chan
 			HostWorkDir: se.Renderer.HostWorkDir,
 			CacheDir:    se.Renderer.CacheDir,
 			OutputsDir:  outputsDir,
+			Mounts:      se.Mounts,
+			Secrets:     se.Secrets,
+
+			TerminalSize: se.getTerminalSize(),
 		},
-		Stream: se.Stream,
+		Stream: stream,
 	}
 }
 
-func (se *StepsExecutor) ExecuteStep(step *definition.Step) error {
+// stepExecution - A step whose runner has been started
+type stepExecution struct {
+	Step       *definition.Step
+	Args       *definition.StepArgs
+	Runner     StepRunner
+	OutputsDir string
+	DoneChan   chan bool
+	ErrorChan  chan error
+}
+
+func (e *stepExecution) cleanUp() {
+	os.RemoveAll(e.OutputsDir)
+}
+
+// drainDone - Runners send done after an error. Receive it so they can exit
+func (e *stepExecution) drainDone() {
+	go func() { <-e.DoneChan }()
+}
+
+func printStepBanner(step *definition.Step) {
 	stepName := ""
 	if step.Name != "" {
 		stepName = fmt.Sprintf("\"%s\"", step.Name)
 	}
 	color.Green("\n>>> Executing %s step %s\n", step.Type, stepName)
+}
 
-	doneChan := make(chan bool)
-	errorChan := make(chan error)
-	args, err := step.Args.ResolveArgs(se.Renderer)
+func printStepFailure(step *definition.Step) {
+	stepName := ""
+	if step.Name != "" {
+		stepName = fmt.Sprintf("\"%s\"", step.Name)
+	}
+	color.Red("\n>>> Failed while executing %s step %s\n", step.Type, stepName)
+}
+
+// skipStep - Checks the step's when condition and announces the step if it is skipped
+func (se *StepsExecutor) skipStep(step *definition.Step) (bool, error) {
+	run, err := step.ShouldRun(se.Renderer)
 	if err != nil {
-		return err
+		return false, err
+	}
+	if !run {
+		log.Debugf("Condition %s for step %s is false", step.When, step.ReferenceName())
+		color.Yellow("\n>>> Skipping %s step %s because its when condition is false\n", step.Type, step.ReferenceName())
+		se.sendStepSkipped(step)
+	}
+	return !run, nil
+}
+
+// startStep - Resolves the arguments for a step and starts its runner
+func (se *StepsExecutor) startStep(step *definition.Step, stream streamer.StepStream) (*stepExecution, error) {
+	args, err := step.ResolveArgs(se.Renderer)
+	if err != nil {
+		return nil, err
 	}
 
 	outputsDir, err := ioutil.TempDir("", "cork-command-outputs-")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer os.RemoveAll(outputsDir)
 
-	params := se.makeStepRunnerParams(doneChan, errorChan, args, outputsDir)
+	execution := &stepExecution{
+		Step:       step,
+		Args:       args,
+		OutputsDir: outputsDir,
+		DoneChan:   make(chan bool),
+		ErrorChan:  make(chan error),
+	}
+
+	params := se.makeStepRunnerParams(execution.DoneChan, execution.ErrorChan, args, outputsDir, stream)
 
-	// Resolve the arguments for the current step
 	runner, err := StepRunners.GetRunner(step.Type, params)
 	if err != nil {
-		return err
+		execution.cleanUp()
+		return nil, err
 	}
+	execution.Runner = runner
 
 	go runner.Run()
+	return execution, nil
+}
+
+// collectOutputs - Makes the outputs of a finished step available to later steps
+func (se *StepsExecutor) collectOutputs(execution *stepExecution) error {
+	step := execution.Step
+	commandName := execution.Args.Command
+	if step.Type == "script" {
+		commandName = step.ReferenceName()
+	}
+	outputs, err := getOutputs(commandName, execution.OutputsDir, step.Outputs)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range step.Outputs {
+		log.Debugf("Retrieving output value for %s from step %s", key, step.Name)
+		value, ok := outputs[key]
+		if !ok {
+			return fmt.Errorf("Expected output value %s missing from step %s", key, step.Name)
+		}
+		log.Debugf("Retrieved output %s=%s from step %s", key, value, step.Name)
+		se.Renderer.AddOutput(step.Name, key, value)
+	}
+	return nil
+}
+
+func (se *StepsExecutor) ExecuteStep(step *definition.Step) error {
+	_, err := se.executeStep(step)
+	return err
+}
+
+// executeStep - Runs a step. Returns the name of the step that failed, which is
+// one of the steps it runs for a parallel step, along with the error
+func (se *StepsExecutor) executeStep(step *definition.Step) (string, error) {
+	skip, err := se.skipStep(step)
+	if err != nil {
+		return step.ReferenceName(), err
+	}
+	if skip {
+		return "", nil
+	}
+
+	if step.Type == "parallel" {
+		started := se.sendStepStarted(step, 1)
+		failedStep, err := se.executeParallelStep(step)
+		se.sendStepFinished(step, 1, started, err)
+		se.matrixResults.Record(step, err)
+		return failedStep, err
+	}
+
+	err = se.retryStep(step, func() error {
+		return se.executeStepAttempt(step)
+	}, se.waitBackoff)
+	se.matrixResults.Record(step, err)
+	if err != nil {
+		return step.ReferenceName(), err
+	}
+	return "", nil
+}
+
+func (se *StepsExecutor) executeStepAttempt(step *definition.Step) error {
+	printStepBanner(step)
+
+	timer, timeout, err := stepTimer(step)
+	if err != nil {
+		return err
+	}
+
+	execution, err := se.startStep(step, se.Stream)
+	if err != nil {
+		return err
+	}
+	defer execution.cleanUp()
 
 	done := false
 
@@ -137,19 +399,24 @@ func (se *StepsExecutor) ExecuteStep(step *definition.Step) error {
 		select {
 		case input := <-se.InputChan:
 			log.Debugf("Received input")
-			se.handleInput(input, runner)
+			se.handleInput(input, execution.Runner)
 		case err := <-se.InputErrorChan:
 			log.Debugf("Received error from user input")
-			color.Red("\n>>> Failed while executing %s step %s\n", step.Type, stepName)
+			printStepFailure(step)
 			return err
-		case err := <-errorChan:
+		case err := <-execution.ErrorChan:
 			log.Debugf("Received error from executing step")
-			color.Red("\n>>> Failed while executing %s step %s\n", step.Type, stepName)
+			execution.drainDone()
+			printStepFailure(step)
 			return err
-		case <-doneChan:
+		case <-execution.DoneChan:
 			log.Debugf("Step execution completed for step %s", step.Name)
 			done = true
 			break
+		case <-timer:
+			err := killTimedOutStep(execution, timeout)
+			printStepFailure(step)
+			return err
 		}
 
 		if done {
@@ -157,21 +424,7 @@ func (se *StepsExecutor) ExecuteStep(step *definition.Step) error {
 		}
 	}
 
-	outputs, err := getOutputs(args.Command, outputsDir, step.Outputs)
-	if err != nil {
-		return err
-	}
-
-	for _, key := range step.Outputs {
-		log.Debugf("Retrieving output value for %s from step %s", key, step.Name)
-		value, ok := outputs[key]
-		if !ok {
-			return fmt.Errorf("Expected output value %s missing from step %s", key, step.Name)
-		}
-		log.Debugf("Retrieved output %s=%s from step %s", key, value, step.Name)
-		se.Renderer.AddOutput(step.Name, key, value)
-	}
-	return nil
+	return se.collectOutputs(execution)
 }
 
 func (se *StepsExecutor) AddOutput(stepName string, varName string, value string) {
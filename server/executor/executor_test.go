@@ -0,0 +1,432 @@
+package executor_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/definition"
+	"github.com/virtru/cork/server/executor"
+)
+
+var lifecycle_definition_yml = `
+version: 1
+
+stages:
+  default:
+    - name: build
+      type: script
+      args:
+        script: echo -n app.tar > $CORK_OUTPUTS_DIR/artifact
+      outputs:
+        - artifact
+
+    - name: publish
+      type: script
+      when: "false"
+      args:
+        script: echo publishing
+
+    - name: test
+      type: script
+      args:
+        script: exit 3
+`
+
+var script_definition_yml = `
+version: 1
+
+params:
+  msg:
+    type: string
+
+stages:
+  default:
+    - name: write
+      type: script
+      args:
+        script: |
+          cat > $CORK_OUTPUTS_DIR/raw <<'EOF'
+          {{ param "msg" }}
+          EOF
+          tr -d '\n' <$CORK_OUTPUTS_DIR/raw > $CORK_OUTPUTS_DIR/message
+      outputs:
+        - message
+`
+
+var timeout_definition_yml = `
+version: 1
+
+params:
+  pid_file:
+    type: string
+
+stages:
+  default:
+    - name: hang
+      type: script
+      timeout: 1s
+      args:
+        script: |
+          trap '' HUP
+          sleep 60 &
+          echo $! > {{ param "pid_file" }}
+          wait
+`
+
+var retry_definition_yml = `
+version: 1
+
+params:
+  attempts_file:
+    type: string
+
+stages:
+  default:
+    - name: flaky
+      type: script
+      retry:
+        attempts: 2
+        on_exit_codes: [1]
+      args:
+        script: |
+          test ! -e $CORK_OUTPUTS_DIR/attempt || exit 9
+          echo attempt >> {{ param "attempts_file" }}
+          wc -l < {{ param "attempts_file" }} | tr -d ' \n' > $CORK_OUTPUTS_DIR/attempt
+          test $(cat $CORK_OUTPUTS_DIR/attempt) -ge 2
+      outputs:
+        - attempt
+`
+
+var parallel_hang_definition_yml = `
+version: 1
+
+params:
+  pid_dir:
+    type: string
+
+stages:
+  default:
+    - name: checks
+      type: parallel
+      steps:
+        - name: lint
+          type: script
+          args:
+            script: |
+              trap '' HUP
+              sleep 60 &
+              echo $! > {{ param "pid_dir" }}/lint
+              wait
+        - name: unit
+          type: script
+          args:
+            script: |
+              trap '' HUP
+              sleep 60 &
+              echo $! > {{ param "pid_dir" }}/unit
+              wait
+`
+
+// recordingStream - Records the events sent by the executor. It never receives input
+type recordingStream struct {
+	lock   sync.Mutex
+	events []*pb.ExecuteOutputEvent
+}
+
+func (s *recordingStream) Recv() (*pb.ExecuteInputEvent, error) {
+	select {}
+}
+
+// brokenInputStream - A recording stream whose client goes away after a delay
+type brokenInputStream struct {
+	recordingStream
+	after time.Duration
+}
+
+func (s *brokenInputStream) Recv() (*pb.ExecuteInputEvent, error) {
+	time.Sleep(s.after)
+	return nil, fmt.Errorf("client went away")
+}
+
+func (s *recordingStream) Send(event *pb.ExecuteOutputEvent) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingStream) ofType(eventType string) []*pb.ExecuteOutputEvent {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var events []*pb.ExecuteOutputEvent
+	for _, event := range s.events {
+		if event.GetType() == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestStepLifecycleEvents(t *testing.T) {
+	def, err := definition.LoadFromString(lifecycle_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stream := &recordingStream{}
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir: "/tmp",
+	})
+	stageExec := executor.NewExecutor("/tmp", renderer, stream, steps)
+	err = stageExec.Execute()
+	assert.Error(t, err)
+	assert.Equal(t, executor.StatusFailed, executor.StageStatus(err))
+
+	if failure, ok := err.(executor.StepFailure); assert.True(t, ok) {
+		assert.Equal(t, "test", failure.Step)
+		code, exited := failure.ExitCode()
+		assert.True(t, exited)
+		assert.Equal(t, 3, code)
+	}
+
+	started := stream.ofType("stepStarted")
+	if assert.Len(t, started, 2) {
+		assert.Equal(t, "build", started[0].GetStepStarted().GetName())
+		assert.Equal(t, int32(1), started[0].GetStepStarted().GetIndex())
+		assert.Equal(t, int32(1), started[0].GetStepStarted().GetAttempt())
+		assert.Equal(t, "test", started[1].GetStepStarted().GetName())
+		assert.Equal(t, int32(3), started[1].GetStepStarted().GetIndex())
+	}
+
+	finished := stream.ofType("stepFinished")
+	if assert.Len(t, finished, 3) {
+		build := finished[0].GetStepFinished()
+		assert.Equal(t, executor.StatusSucceeded, build.GetStatus())
+		assert.Equal(t, "script", build.GetType())
+		assert.Equal(t, map[string]string{"artifact": "app.tar"}, build.GetOutputs())
+
+		publish := finished[1].GetStepFinished()
+		assert.Equal(t, "publish", publish.GetName())
+		assert.Equal(t, executor.StatusSkipped, publish.GetStatus())
+
+		test := finished[2].GetStepFinished()
+		assert.Equal(t, executor.StatusFailed, test.GetStatus())
+		assert.Equal(t, int32(3), test.GetExitCode())
+		assert.NotEmpty(t, test.GetError())
+	}
+}
+
+func TestHookSteps(t *testing.T) {
+	corkDir, err := ioutil.TempDir("", "cork-dir")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(corkDir)
+	assert.NoError(t, os.Mkdir(path.Join(corkDir, "hooks"), 0700))
+	assert.NoError(t, ioutil.WriteFile(path.Join(corkDir, "hooks", "init"), []byte("#!/bin/sh\nexit 4\n"), 0700))
+
+	hook := &definition.Step{
+		Name: "init",
+		Type: "hook",
+		Args: definition.StepArgs{
+			Command: "init",
+		},
+	}
+	stream := &recordingStream{}
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir: "/tmp",
+	})
+	err = executor.NewExecutor(corkDir, renderer, stream, []*definition.Step{hook}).Execute()
+	if failure, ok := err.(executor.StepFailure); assert.True(t, ok) {
+		assert.Equal(t, "init", failure.Step)
+		code, _ := failure.ExitCode()
+		assert.Equal(t, 4, code)
+	}
+
+	_, err = executor.LoadHook(corkDir, "startup")
+	assert.True(t, executor.IsCommandDoesNotExist(err))
+}
+
+func TestScriptSteps(t *testing.T) {
+	def, err := definition.LoadFromString(script_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Scripts are rendered as plain text so redirects and quotes are kept
+	stream := &recordingStream{}
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir:    "/tmp",
+		UserParams: map[string]string{"msg": `it's "a" & <b>`},
+	})
+	err = executor.NewExecutor("/tmp", renderer, stream, steps).Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	finished := stream.ofType("stepFinished")
+	if assert.Len(t, finished, 1) {
+		assert.Equal(t, executor.StatusSucceeded, finished[0].GetStepFinished().GetStatus())
+		assert.Equal(t, map[string]string{"message": `it's "a" & <b>`}, finished[0].GetStepFinished().GetOutputs())
+	}
+}
+
+// processRunning - Checks if a process is alive. Zombies that were not reaped yet
+// have stopped running
+func processRunning(pid int) bool {
+	stat, err := ioutil.ReadFile(path.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestStepTimeoutKillsProcessGroup(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "cork-timeout")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(tempDir)
+	pidFile := path.Join(tempDir, "pid")
+
+	def, err := definition.LoadFromString(timeout_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stream := &recordingStream{}
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir:    "/tmp",
+		UserParams: map[string]string{"pid_file": pidFile},
+	})
+	err = executor.NewExecutor("/tmp", renderer, stream, steps).Execute()
+	failure, ok := err.(executor.StepFailure)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.IsType(t, executor.StepTimeout{}, failure.Err)
+	finished := stream.ofType("stepFinished")
+	if assert.Len(t, finished, 1) {
+		assert.Equal(t, executor.StatusFailed, finished[0].GetStepFinished().GetStatus())
+		assert.Equal(t, int32(-1), finished[0].GetStepFinished().GetExitCode())
+	}
+
+	// The process the script started in the background is killed with it, even
+	// though it ignores the hangup sent when the script's session ends
+	pidBytes, err := ioutil.ReadFile(pidFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for processRunning(pid) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.False(t, processRunning(pid), "background process of the step should be killed")
+}
+
+func TestStepRetryUsesFreshOutputsDir(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "cork-retry")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	def, err := definition.LoadFromString(retry_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stream := &recordingStream{}
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir:    "/tmp",
+		UserParams: map[string]string{"attempts_file": path.Join(tempDir, "attempts")},
+	})
+	err = executor.NewExecutor("/tmp", renderer, stream, steps).Execute()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The first attempt fails and the outputs it left behind are not seen by the second
+	finished := stream.ofType("stepFinished")
+	if assert.Len(t, finished, 2) {
+		assert.Equal(t, executor.StatusFailed, finished[0].GetStepFinished().GetStatus())
+		assert.Equal(t, executor.StatusSucceeded, finished[1].GetStepFinished().GetStatus())
+		assert.Equal(t, int32(2), finished[1].GetStepFinished().GetAttempt())
+		assert.Equal(t, map[string]string{"attempt": "2"}, finished[1].GetStepFinished().GetOutputs())
+	}
+}
+
+func TestParallelStepStopsOnInputError(t *testing.T) {
+	pidDir, err := ioutil.TempDir("", "cork-parallel")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(pidDir)
+
+	def, err := definition.LoadFromString(parallel_hang_definition_yml)
+	if !assert.NoError(t, err) {
+		return
+	}
+	steps, err := def.ListSteps("default")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stream := &brokenInputStream{after: 500 * time.Millisecond}
+	renderer := definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
+		WorkDir:    "/tmp",
+		UserParams: map[string]string{"pid_dir": pidDir},
+	})
+	err = executor.NewExecutor("/tmp", renderer, stream, steps).Execute()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "client went away")
+	}
+
+	// Both branches were finished before the parallel step returned
+	assert.Len(t, stream.ofType("stepFinished"), 3)
+	for _, branch := range []string{"lint", "unit"} {
+		pidBytes, err := ioutil.ReadFile(path.Join(pidDir, branch))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for processRunning(pid) && time.Now().Before(deadline) {
+			time.Sleep(50 * time.Millisecond)
+		}
+		assert.False(t, processRunning(pid), "background process of %s should be killed", branch)
+	}
+}
@@ -3,6 +3,7 @@ package executor
 import (
 	log "github.com/sirupsen/logrus"
 	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/capture"
 	"github.com/virtru/cork/server/definition"
 	"github.com/virtru/cork/server/streamer"
 )
@@ -42,7 +43,7 @@ func (e *ExportStepRunner) Run() {
 		Body: &pb.ExecuteOutputEvent_Export{
 			Export: &pb.ExportEvent{
 				Name:  export.Name,
-				Value: export.Value,
+				Value: capture.RedactString(e.Params.Context.Secrets, export.Value),
 			},
 		},
 	}
@@ -57,14 +58,23 @@ func (e *ExportStepRunner) HandleInput(bytes []byte) error {
 	return nil
 }
 
+// HandleSignal - Exports finish right away so there is nothing to signal
 func (e *ExportStepRunner) HandleSignal(signal int32) error {
 	return nil
 }
 
+func (e *ExportStepRunner) HandleResize(size streamer.TerminalSize) error {
+	return nil
+}
+
+func (e *ExportStepRunner) Kill() error {
+	return nil
+}
+
 // ExportStepHandler - Handles exporting variables from a stage execution
 func ExportStepHandler(corkDir string, executor *StepsExecutor, stream streamer.StepStream, step *definition.Step) (map[string]string, error) {
 	log.Debugf("Running export step %s", step.Name)
-	args, err := step.Args.ResolveArgs(executor.Renderer)
+	args, err := step.ResolveArgs(executor.Renderer)
 	if err != nil {
 		log.Debugf("Error resolving arguments: %v", err)
 		return nil, err
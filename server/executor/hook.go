@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"path"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterRunner("hook", HookStepRunnerFactory)
+}
+
+func HookStepRunnerFactory(params StepRunnerParams) (StepRunner, error) {
+	runner := &HookStepRunner{}
+
+	err := runner.Initialize(params)
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
+
+// HookStepRunner - Runs a hook from the CORK_DIR/hooks directory like a command.
+// Hook steps can't be written in a definition. The server creates them
+type HookStepRunner struct {
+	CommandStepRunner
+}
+
+func (h *HookStepRunner) Initialize(params StepRunnerParams) error {
+	h.setParams(params)
+
+	hook, err := LoadHook(params.Context.CorkDir, params.Args.Command)
+	if err != nil {
+		return err
+	}
+	h.Cmd = hook.ExecCommand()
+	return nil
+}
+
+// LoadHook - Loads a hook from the CORK_DIR/hooks directory
+func LoadHook(corkDir string, name string) (*Command, error) {
+	hookPath := path.Join(corkDir, "hooks", name)
+	log.Debugf("Loading hook %s from %s", name, hookPath)
+
+	err := CheckCommandPath(name, hookPath, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Command{
+		Name: name,
+		Path: hookPath,
+	}, nil
+}
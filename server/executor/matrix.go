@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/virtru/cork/server/definition"
+)
+
+// matrixResults - Tracks the result of each matrix combination in a run. A
+// combination fails if any of its steps fail
+type matrixResults struct {
+	lock         sync.Mutex
+	combinations []*definition.MatrixCombination
+	results      map[*definition.MatrixCombination]string
+}
+
+func newMatrixResults(stepLists ...[]*definition.Step) *matrixResults {
+	results := &matrixResults{
+		results: map[*definition.MatrixCombination]string{},
+	}
+	for _, steps := range stepLists {
+		results.addCombinations(steps)
+	}
+	return results
+}
+
+// addCombinations - Lists the combinations of the steps in the order they run.
+// Enclosing combinations are listed before the combinations nested in them
+func (r *matrixResults) addCombinations(steps []*definition.Step) {
+	for _, step := range steps {
+		var chain []*definition.MatrixCombination
+		for combination := step.Combination; combination != nil; combination = combination.Parent() {
+			chain = append([]*definition.MatrixCombination{combination}, chain...)
+		}
+		for _, combination := range chain {
+			if _, ok := r.results[combination]; ok {
+				continue
+			}
+			r.results[combination] = "not run"
+			r.combinations = append(r.combinations, combination)
+		}
+		for _, branch := range step.Branches {
+			r.addCombinations(branch)
+		}
+	}
+}
+
+// Record - Records the result of a step that ran
+func (r *matrixResults) Record(step *definition.Step, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for combination := step.Combination; combination != nil; combination = combination.Parent() {
+		if err != nil {
+			r.results[combination] = "failed"
+		} else if r.results[combination] != "failed" {
+			r.results[combination] = "passed"
+		}
+	}
+}
+
+// Print - Prints the result of each combination. Nothing is printed if the run
+// had no matrix
+func (r *matrixResults) Print() {
+	if r == nil || len(r.combinations) == 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	color.Blue("\n>>> Matrix results\n")
+	for _, combination := range r.combinations {
+		result := r.results[combination]
+		switch result {
+		case "passed":
+			color.Green("    %s: %s\n", combination.Name(), result)
+		case "failed":
+			color.Red("    %s: %s\n", combination.Name(), result)
+		default:
+			color.Yellow("    %s: %s\n", combination.Name(), result)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/definition"
+	"github.com/virtru/cork/server/streamer"
+)
+
+// parallelGroup - Tracks the branches of a running parallel step
+type parallelGroup struct {
+	Semaphore chan bool
+	Results   chan error
+
+	lock       sync.Mutex
+	failed     bool
+	failedStep string
+	runners    map[StepRunner]bool
+
+	// Closed when the parallel step is stopped before its branches finish
+	aborted   chan bool
+	abortOnce sync.Once
+}
+
+func newParallelGroup(step *definition.Step) *parallelGroup {
+	concurrency := step.Concurrency
+	if concurrency <= 0 || concurrency > len(step.Branches) {
+		concurrency = len(step.Branches)
+	}
+	return &parallelGroup{
+		Semaphore: make(chan bool, concurrency),
+		Results:   make(chan error, len(step.Branches)),
+		runners:   map[StepRunner]bool{},
+		aborted:   make(chan bool),
+	}
+}
+
+// Abort - Stops the group. Branches start no new steps and kill the steps they
+// are running
+func (g *parallelGroup) Abort() {
+	g.lock.Lock()
+	g.failed = true
+	g.lock.Unlock()
+	g.abortOnce.Do(func() { close(g.aborted) })
+}
+
+// waitBackoff - Waits between the attempts of a branch's step. The step is not
+// retried if the group is stopped
+func (g *parallelGroup) waitBackoff(backoff time.Duration) (bool, error) {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true, nil
+	case <-g.aborted:
+		return false, nil
+	}
+}
+
+// Fail - Marks the group as failed. The first step to fail is kept
+func (g *parallelGroup) Fail(step *definition.Step) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.failed {
+		g.failedStep = step.ReferenceName()
+	}
+	g.failed = true
+}
+
+func (g *parallelGroup) FailedStep() string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.failedStep
+}
+
+func (g *parallelGroup) Failed() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.failed
+}
+
+func (g *parallelGroup) AddRunner(runner StepRunner) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.runners[runner] = true
+}
+
+func (g *parallelGroup) RemoveRunner(runner StepRunner) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	delete(g.runners, runner)
+}
+
+func (g *parallelGroup) Runners() []StepRunner {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	var runners []StepRunner
+	for runner := range g.runners {
+		runners = append(runners, runner)
+	}
+	return runners
+}
+
+// executeParallelStep - Runs the branches of a parallel step concurrently. Once a
+// branch fails no new steps are started and the first error is returned after the
+// running steps finish along with the name of the step that failed
+func (se *StepsExecutor) executeParallelStep(step *definition.Step) (string, error) {
+	printStepBanner(step)
+
+	group := newParallelGroup(step)
+	for _, branch := range step.Branches {
+		go func(branch []*definition.Step) {
+			group.Results <- se.executeBranch(group, branch)
+		}(branch)
+	}
+
+	var firstErr error
+	for remaining := len(step.Branches); remaining > 0; {
+		select {
+		case input := <-se.InputChan:
+			se.handleParallelInput(input, group)
+		case err := <-se.InputErrorChan:
+			log.Debugf("Received error from user input. Stopping the parallel steps")
+			group.Abort()
+			for ; remaining > 0; remaining-- {
+				<-group.Results
+			}
+			printStepFailure(step)
+			return step.ReferenceName(), err
+		case err := <-group.Results:
+			remaining--
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		printStepFailure(step)
+		return group.FailedStep(), firstErr
+	}
+	return "", nil
+}
+
+func (se *StepsExecutor) executeBranch(group *parallelGroup, branch []*definition.Step) error {
+	group.Semaphore <- true
+	defer func() { <-group.Semaphore }()
+
+	for _, step := range branch {
+		if group.Failed() {
+			log.Debugf("Skipping step %s because a parallel step failed", step.ReferenceName())
+			return nil
+		}
+		err := se.executeBranchStep(group, step)
+		if err != nil {
+			group.Fail(step)
+			return err
+		}
+	}
+	return nil
+}
+
+func (se *StepsExecutor) executeBranchStep(group *parallelGroup, step *definition.Step) error {
+	skip, err := se.skipStep(step)
+	if err != nil || skip {
+		return err
+	}
+
+	err = se.retryStep(step, func() error {
+		return se.executeBranchStepAttempt(group, step)
+	}, group.waitBackoff)
+	se.matrixResults.Record(step, err)
+	return err
+}
+
+func (se *StepsExecutor) executeBranchStepAttempt(group *parallelGroup, step *definition.Step) error {
+	printStepBanner(step)
+
+	timer, timeout, err := stepTimer(step)
+	if err != nil {
+		return err
+	}
+
+	stream := streamer.NewTaggedStream(se.Stream, step.ReferenceName())
+	execution, err := se.startStep(step, stream)
+	if err != nil {
+		return err
+	}
+	defer execution.cleanUp()
+
+	group.AddRunner(execution.Runner)
+	defer group.RemoveRunner(execution.Runner)
+
+	select {
+	case err := <-execution.ErrorChan:
+		execution.drainDone()
+		printStepFailure(step)
+		return err
+	case <-execution.DoneChan:
+		log.Debugf("Step execution completed for step %s", step.Name)
+	case <-timer:
+		err := killTimedOutStep(execution, timeout)
+		printStepFailure(step)
+		return err
+	case <-group.aborted:
+		log.Debugf("Killing step %s because the parallel step was stopped", step.ReferenceName())
+		killStep(execution)
+		return fmt.Errorf(`Step "%s" was stopped`, step.ReferenceName())
+	}
+
+	return se.collectOutputs(execution)
+}
+
+func (se *StepsExecutor) handleParallelInput(input *pb.ExecuteInputEvent, group *parallelGroup) {
+	switch input.GetType() {
+	case "signal":
+		signal := input.GetSignal().GetSignal()
+		log.Debugf("Sending signal %d to all running parallel steps", signal)
+		for _, runner := range group.Runners() {
+			runner.HandleSignal(signal)
+		}
+	case "resize":
+		size := se.getTerminalSize()
+		for _, runner := range group.Runners() {
+			runner.HandleResize(size)
+		}
+	case "input":
+		log.Debugf("Input is ignored while steps run in parallel")
+	}
+}
@@ -0,0 +1,168 @@
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+	"github.com/virtru/cork/server/definition"
+)
+
+// How long a killed step has to finish before it is abandoned
+const killGracePeriod = 10 * time.Second
+
+// StepTimeout - A step ran longer than its timeout and was killed
+type StepTimeout struct {
+	Name    string
+	Timeout time.Duration
+	Message string
+}
+
+func (st StepTimeout) Error() string {
+	return st.Message
+}
+
+func createStepTimeoutError(step *definition.Step, timeout time.Duration) StepTimeout {
+	return StepTimeout{
+		Name:    step.ReferenceName(),
+		Timeout: timeout,
+		Message: fmt.Sprintf(`Step "%s" timed out after %s`, step.ReferenceName(), timeout),
+	}
+}
+
+// StepExitError - A step's process exited with a non-zero status
+type StepExitError struct {
+	Code    int
+	Message string
+}
+
+func (see StepExitError) Error() string {
+	return see.Message
+}
+
+// exitCodeOf - Finds the exit code of a step that failed by exiting
+func exitCodeOf(err error) (int, bool) {
+	switch exitErr := err.(type) {
+	case StepExitError:
+		return exitErr.Code, true
+	case *exec.ExitError:
+		status, ok := exitErr.Sys().(syscall.WaitStatus)
+		if !ok {
+			return 0, false
+		}
+		return status.ExitStatus(), true
+	}
+	return 0, false
+}
+
+// shouldRetry - Checks if an error from a step is allowed to be retried by its policy.
+// Only timeouts and non-zero exits are retried. Anything else will fail again
+func shouldRetry(policy *definition.RetryPolicy, err error) bool {
+	if _, ok := err.(StepTimeout); ok {
+		return policy.RetriesTimeout()
+	}
+	code, ok := exitCodeOf(err)
+	if !ok {
+		return false
+	}
+	return policy.RetriesExitCode(code)
+}
+
+// backoffWait - Waits between the attempts of a step. Returns false if the step
+// should not be retried after all
+type backoffWait func(backoff time.Duration) (bool, error)
+
+// waitBackoff - Waits between attempts while handling input from the client. No
+// step is running to forward a signal to, so a signal stops the retries instead
+func (se *StepsExecutor) waitBackoff(backoff time.Duration) (bool, error) {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return true, nil
+		case input := <-se.InputChan:
+			if input.GetType() == "signal" {
+				log.Debugf("Received signal %d during backoff. Not retrying", input.GetSignal().GetSignal())
+				return false, nil
+			}
+			log.Debugf("Input is ignored while waiting to retry a step")
+		case err := <-se.InputErrorChan:
+			log.Debugf("Received error from user input")
+			return false, err
+		}
+	}
+}
+
+// retryStep - Runs a step's attempts until one succeeds or the retry policy gives up
+func (se *StepsExecutor) retryStep(step *definition.Step, attempt func() error, wait backoffWait) error {
+	policy := step.Retry
+	maxAttempts := policy.MaxAttempts()
+	backoff, err := policy.BackoffDuration()
+	if err != nil {
+		return err
+	}
+
+	for attemptNumber := 1; ; attemptNumber++ {
+		started := se.sendStepStarted(step, attemptNumber)
+		err = attempt()
+		se.sendStepFinished(step, attemptNumber, started, err)
+		if err == nil {
+			return nil
+		}
+		if attemptNumber >= maxAttempts || !shouldRetry(policy, err) {
+			return err
+		}
+		log.Debugf("Attempt %d of step %s failed: %v", attemptNumber, step.ReferenceName(), err)
+		color.Yellow("\n>>> Retrying step \"%s\" in %s (attempt %d of %d)\n", step.ReferenceName(), backoff, attemptNumber+1, maxAttempts)
+		retry, waitErr := wait(backoff)
+		if waitErr != nil {
+			return waitErr
+		}
+		if !retry {
+			return err
+		}
+	}
+}
+
+// stepTimer - Creates a channel that fires when the step's timeout passes. The
+// channel is nil, and never fires, for steps without a timeout
+func stepTimer(step *definition.Step) (<-chan time.Time, time.Duration, error) {
+	timeout, err := step.TimeoutDuration()
+	if err != nil {
+		return nil, 0, err
+	}
+	if timeout == 0 {
+		return nil, 0, nil
+	}
+	return time.After(timeout), timeout, nil
+}
+
+// killTimedOutStep - Kills a step that ran past its timeout and waits for its
+// runner to finish
+func killTimedOutStep(execution *stepExecution, timeout time.Duration) error {
+	step := execution.Step
+	log.Debugf("Step %s timed out after %s. Killing it", step.ReferenceName(), timeout)
+	killStep(execution)
+	return createStepTimeoutError(step, timeout)
+}
+
+// killStep - Kills a running step and waits for its runner to finish
+func killStep(execution *stepExecution) {
+	step := execution.Step
+	err := execution.Runner.Kill()
+	if err != nil {
+		log.Debugf("Error killing step %s: %v", step.ReferenceName(), err)
+	}
+
+	select {
+	case <-execution.ErrorChan:
+		execution.drainDone()
+	case <-execution.DoneChan:
+	case <-time.After(killGracePeriod):
+		log.Debugf("Step %s did not finish after being killed", step.ReferenceName())
+	}
+}
@@ -18,6 +18,8 @@ type StepRunner interface {
 	Run()
 	HandleInput(bytes []byte) error
 	HandleSignal(signal int32) error
+	HandleResize(size streamer.TerminalSize) error
+	Kill() error
 }
 
 func (s StepRunnersMap) GetRunner(name string, params StepRunnerParams) (StepRunner, error) {
@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/kballard/go-shellquote"
+	log "github.com/sirupsen/logrus"
+)
+
+// The interpreter of scripts that don't set a shell
+const defaultScriptShell = "/bin/sh"
+
+func init() {
+	RegisterRunner("script", ScriptStepRunnerFactory)
+}
+
+func ScriptStepRunnerFactory(params StepRunnerParams) (StepRunner, error) {
+	runner := &ScriptStepRunner{}
+
+	err := runner.Initialize(params)
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
+
+// ScriptStepRunner - Runs a script written in the definition. The script runs
+// like a command, with the same environment and outputs directory
+type ScriptStepRunner struct {
+	CommandStepRunner
+	ScriptPath string
+}
+
+func (s *ScriptStepRunner) Initialize(params StepRunnerParams) error {
+	s.setParams(params)
+
+	shell := params.Args.Shell
+	if shell == "" {
+		shell = defaultScriptShell
+	}
+	shellArgs, err := shellquote.Split(shell)
+	if err != nil || len(shellArgs) == 0 {
+		return fmt.Errorf(`Invalid script shell "%s"`, shell)
+	}
+
+	scriptFile, err := ioutil.TempFile("", "cork-script-")
+	if err != nil {
+		return err
+	}
+	defer scriptFile.Close()
+	s.ScriptPath = scriptFile.Name()
+
+	_, err = scriptFile.WriteString(params.Args.Script)
+	if err != nil {
+		os.Remove(s.ScriptPath)
+		return err
+	}
+	err = scriptFile.Chmod(0700)
+	if err != nil {
+		os.Remove(s.ScriptPath)
+		return err
+	}
+
+	log.Debugf("Running script %s with %v", s.ScriptPath, shellArgs)
+	s.Cmd = exec.Command(shellArgs[0], append(shellArgs[1:], s.ScriptPath)...)
+	s.Cmd.Env = os.Environ()
+	return nil
+}
+
+func (s *ScriptStepRunner) Run() {
+	defer os.Remove(s.ScriptPath)
+	s.CommandStepRunner.Run()
+}
@@ -7,15 +7,19 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"sort"
 	"time"
 
 	"google.golang.org/grpc"
 
+	uuid "github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/capture"
 	"github.com/virtru/cork/server/definition"
 	"github.com/virtru/cork/server/environment"
 	"github.com/virtru/cork/server/executor"
+	"github.com/virtru/cork/server/runs"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"gopkg.in/urfave/cli.v1"
@@ -31,11 +35,18 @@ type CorkTypeServer struct {
 	WorkDir             string
 	HostWorkDir         string
 	CacheDir            string
+	VolumePrefix        string
 	ProjectName         string
 	IsInitialized       bool
 	InitializationError error
 }
 
+// The stage or hook that initializes a project
+const (
+	initStageName = "init"
+	initHookName  = "init"
+)
+
 type ServerStream interface {
 	Send(event *pb.ExecuteOutputEvent) error
 }
@@ -83,31 +94,143 @@ func (c *CorkTypeServer) StageExecute(stream pb.CorkTypeService_StageExecuteServ
 	if inputEvent.GetType() != "stageExecuteRequest" {
 		return fmt.Errorf("Fatal error. Expected stage execution request before anything else")
 	}
-	stageExecuteRequest := inputEvent.GetBody().(*pb.ExecuteInputEvent_StageExecuteRequest)
-	stage := stageExecuteRequest.StageExecuteRequest.GetStage()
+	stageExecuteRequest := inputEvent.GetBody().(*pb.ExecuteInputEvent_StageExecuteRequest).StageExecuteRequest
+	stage := stageExecuteRequest.GetStage()
+	tags := stageExecuteRequest.GetTags()
+	runID := stageExecuteRequest.GetRunId()
+	if runID == "" {
+		runID = uuid.NewV4().String()
+	}
+	selection := definition.StepSelection{
+		From:  stageExecuteRequest.GetFrom(),
+		Until: stageExecuteRequest.GetUntil(),
+		Only:  stageExecuteRequest.GetOnly(),
+	}
 
-	requiredParams, err := c.ServerDefinition.RequiredUserParamsForStage(stage)
+	// Steps that are not selected are skipped. Their outputs come from the latest run
+	runStore := runs.NewStore(c.CacheDir)
+	var previousOutputs map[string]map[string]string
+	if !selection.IsEmpty() {
+		previousRun, err := runStore.Latest(stage)
+		if err != nil {
+			kind := pb.ErrorKindInfrastructure
+			if runs.IsRunNotFound(err) {
+				kind = pb.ErrorKindDefinition
+			}
+			sendError(stream, kind, err)
+			return err
+		}
+		log.Debugf("Running stage %s %s with the outputs of run %s", stage, selection, previousRun.RunID)
+		previousOutputs = previousRun.Outputs
+	}
+
+	steps, previousOutputs, err := c.ServerDefinition.ListSelectedSteps(stage, tags, selection, previousOutputs)
 	if err != nil {
+		sendError(stream, pb.ErrorKindDefinition, err)
 		return err
 	}
-	paramDefinitions := make(map[string]*pb.ParamDefinition)
-	for _, paramName := range requiredParams {
-		variableDefinition, ok := c.ServerDefinition.Params[paramName]
-		if !ok {
-			return fmt.Errorf(`Fatal error. Param definition could not be found for "%s"`, paramName)
-		}
 
-		varDefault := ""
-		if variableDefinition.Default != nil {
-			varDefault = *variableDefinition.Default
+	hooks, err := c.ServerDefinition.ListHooksWithTags(stage, tags)
+	if err != nil {
+		sendError(stream, pb.ErrorKindDefinition, err)
+		return err
+	}
+
+	return c.runSteps(stream, &stepsRun{
+		Stage:           stage,
+		Tags:            tags,
+		Steps:           steps,
+		Hooks:           hooks,
+		PreviousOutputs: previousOutputs,
+		AfterExecute: func(renderer *definition.CorkTemplateRenderer) {
+			// Keep the outputs of the steps that completed so the run can be resumed
+			err := runStore.Save(&runs.RunOutputs{
+				RunID:   runID,
+				Stage:   stage,
+				Outputs: renderer.ListOutputs(),
+			})
+			if err != nil {
+				log.Debugf("Could not save the outputs of run %s: %v", runID, err)
+			}
+		},
+	})
+}
+
+// Initialize - Initializes a project. The type's init stage runs if it has one.
+// Otherwise its hooks/init runs if it has one
+func (c *CorkTypeServer) Initialize(stream pb.CorkTypeService_InitializeServer) error {
+	if err := c.CheckInitialization(); err != nil {
+		return err
+	}
+	inputEvent, err := stream.Recv()
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("Fatal error. Never started initialization")
 		}
+		return err
+	}
+	if inputEvent.GetType() != "initializeRequest" {
+		return fmt.Errorf("Fatal error. Expected initialize request before anything else")
+	}
 
-		paramDefinitions[paramName] = &pb.ParamDefinition{
-			Type:        variableDefinition.Type,
-			Default:     varDefault,
-			HasDefault:  variableDefinition.HasDefault(),
-			Description: variableDefinition.Description,
+	run := &stepsRun{
+		Stage: initStageName,
+		Hooks: &definition.StageHooks{},
+	}
+	if _, ok := c.ServerDefinition.Stages[initStageName]; ok {
+		run.Steps, err = c.ServerDefinition.ListStepsWithTags(initStageName, nil)
+		if err != nil {
+			sendError(stream, pb.ErrorKindDefinition, err)
+			return err
+		}
+		run.Hooks, err = c.ServerDefinition.ListHooksWithTags(initStageName, nil)
+		if err != nil {
+			sendError(stream, pb.ErrorKindDefinition, err)
+			return err
 		}
+	} else if _, err := executor.LoadHook(c.CorkDir, initHookName); err == nil {
+		run.Steps = []*definition.Step{
+			{
+				Name: initHookName,
+				Type: "hook",
+				Args: definition.StepArgs{
+					Command: initHookName,
+				},
+			},
+		}
+	} else if !executor.IsCommandDoesNotExist(err) {
+		sendError(stream, pb.ErrorKindInfrastructure, err)
+		return err
+	} else {
+		log.Debugf("The cork type has no init stage or init hook")
+	}
+	return c.runSteps(stream, run)
+}
+
+// stepsRun - The resolved steps to run for a request
+type stepsRun struct {
+	Stage           string
+	Tags            []string
+	Steps           []*definition.Step
+	Hooks           *definition.StageHooks
+	PreviousOutputs map[string]map[string]string
+
+	// Called after the steps run and before the client is told that they ended
+	AfterExecute func(renderer *definition.CorkTemplateRenderer)
+}
+
+// runSteps - Asks the client for the params the steps use, runs the steps and
+// tells the client how they ended
+func (c *CorkTypeServer) runSteps(stream pb.CorkTypeService_StageExecuteServer, run *stepsRun) error {
+	// Only ask for the params used by the steps that will run
+	requiredParams, err := c.ServerDefinition.RequiredUserParamsForSteps(run.Stage, run.Steps, run.Hooks, run.PreviousOutputs)
+	if err != nil {
+		sendError(stream, pb.ErrorKindDefinition, err)
+		return err
+	}
+	paramDefinitions, err := c.paramDefinitions(requiredParams)
+	if err != nil {
+		return err
 	}
 
 	stream.Send(&pb.ExecuteOutputEvent{
@@ -119,7 +242,7 @@ func (c *CorkTypeServer) StageExecute(stream pb.CorkTypeService_StageExecuteServ
 		},
 	})
 
-	inputEvent, err = stream.Recv()
+	inputEvent, err := stream.Recv()
 	if err != nil {
 		if err == io.EOF {
 			return fmt.Errorf("Fatal error. Never started execution")
@@ -133,16 +256,47 @@ func (c *CorkTypeServer) StageExecute(stream pb.CorkTypeService_StageExecuteServ
 	paramsResponseEvent := inputEvent.GetParamsResponse()
 	params := paramsResponseEvent.GetParams()
 
-	steps, err := c.ServerDefinition.ListSteps(stage)
-	log.Debugf("Executing stage: %s with %d steps", stage, len(steps))
+	err = definition.CheckParamValuesProvided(requiredParams, params)
 	if err != nil {
+		sendError(stream, pb.ErrorKindDefinition, err)
 		return err
 	}
 
+	err = c.ServerDefinition.ValidateParamValues(params)
+	if err != nil {
+		sendError(stream, pb.ErrorKindDefinition, err)
+		return err
+	}
+
+	log.Debugf("Executing stage: %s with %d steps for tags %v", run.Stage, len(run.Steps), run.Tags)
+
 	renderer := c.createTemplateRenderer(params)
+	for stepName, stepOutputs := range run.PreviousOutputs {
+		for outputName, value := range stepOutputs {
+			renderer.AddOutput(stepName, outputName, value)
+		}
+	}
+
+	mounts, err := c.ServerDefinition.VolumeMounts(renderer)
+	if err != nil {
+		return err
+	}
 
-	stageExec := executor.NewExecutor(c.CorkDir, renderer, stream, steps)
+	stageExec := executor.NewExecutor(c.CorkDir, renderer, stream, run.Steps)
+	stageExec.Mounts = mounts
+	stageExec.Secrets = c.ServerDefinition.SensitiveValues(params)
+	stageExec.OnFailure = run.Hooks.OnFailure
+	stageExec.Finally = run.Hooks.Finally
+	started := time.Now()
 	err = stageExec.Execute()
+	if run.AfterExecute != nil {
+		run.AfterExecute(renderer)
+	}
+
+	if err != nil {
+		sendErrorEvent(stream, stageErrorEvent(err, stageExec.Secrets))
+	}
+	sendEnd(stream, run.Tags, started, err, stageExec.Secrets)
 	if err != nil {
 		log.Debugf("Error occurred executing stage")
 		return err
@@ -150,12 +304,195 @@ func (c *CorkTypeServer) StageExecute(stream pb.CorkTypeService_StageExecuteServ
 	return nil
 }
 
+// paramDefinitions - Describes params to the client
+func (c *CorkTypeServer) paramDefinitions(paramNames []string) (map[string]*pb.ParamDefinition, error) {
+	paramDefinitions := make(map[string]*pb.ParamDefinition)
+	for _, paramName := range paramNames {
+		variableDefinition, ok := c.ServerDefinition.Params[paramName]
+		if !ok {
+			return nil, fmt.Errorf(`Fatal error. Param definition could not be found for "%s"`, paramName)
+		}
+
+		varDefault := ""
+		if variableDefinition.Default != nil {
+			varDefault = *variableDefinition.Default
+		}
+
+		paramDefinitions[paramName] = &pb.ParamDefinition{
+			Type:        variableDefinition.Type,
+			Default:     varDefault,
+			HasDefault:  variableDefinition.HasDefault(),
+			Description: variableDefinition.Description,
+			IsSensitive: variableDefinition.IsSensitive,
+			Choices:     variableDefinition.Choices,
+			Pattern:     variableDefinition.Pattern,
+		}
+	}
+	return paramDefinitions, nil
+}
+
+func (c *CorkTypeServer) ListStages(ctx context.Context, req *pb.ListStagesRequest) (*pb.ListStagesResponse, error) {
+	if err := c.CheckInitialization(); err != nil {
+		return nil, err
+	}
+
+	stageNames := c.ServerDefinition.ListStages()
+	sort.Strings(stageNames)
+
+	var stages []*pb.StageSummary
+	for _, stageName := range stageNames {
+		stages = append(stages, &pb.StageSummary{
+			Name:        stageName,
+			Description: c.ServerDefinition.Stages[stageName].Description,
+		})
+	}
+	return &pb.ListStagesResponse{
+		Stages: stages,
+	}, nil
+}
+
+func (c *CorkTypeServer) ListParams(ctx context.Context, req *pb.ListParamsRequest) (*pb.ListParamsResponse, error) {
+	if err := c.CheckInitialization(); err != nil {
+		return nil, err
+	}
+
+	var paramNames []string
+	for paramName := range c.ServerDefinition.Params {
+		paramNames = append(paramNames, paramName)
+	}
+	paramDefinitions, err := c.paramDefinitions(paramNames)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListParamsResponse{
+		Params: paramDefinitions,
+	}, nil
+}
+
+func (c *CorkTypeServer) DescribeStage(ctx context.Context, req *pb.DescribeStageRequest) (*pb.DescribeStageResponse, error) {
+	if err := c.CheckInitialization(); err != nil {
+		return nil, err
+	}
+
+	stageName := req.GetStage()
+	steps, err := c.ServerDefinition.ListStepsWithTags(stageName, req.GetTags())
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	hooks, err := c.ServerDefinition.ListHooksWithTags(stageName, req.GetTags())
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	requiredParams, err := c.ServerDefinition.RequiredUserParamsForSteps(stageName, steps, hooks, nil)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	paramDefinitions, err := c.paramDefinitions(requiredParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DescribeStageResponse{
+		Name:        stageName,
+		Description: c.ServerDefinition.Stages[stageName].Description,
+		Steps:       stepsToProto(steps),
+		OnFailure:   stepsToProto(hooks.OnFailure),
+		Finally:     stepsToProto(hooks.Finally),
+		Params:      paramDefinitions,
+	}, nil
+}
+
+func stepsToProto(steps []*definition.Step) []*pb.Step {
+	var pbSteps []*pb.Step
+	for _, step := range steps {
+		pbStep := &pb.Step{
+			Name: step.Name,
+			Type: step.Type,
+			When: step.When,
+		}
+		for _, branch := range step.Branches {
+			pbStep.Branches = append(pbStep.Branches, &pb.StepBranch{
+				Steps: stepsToProto(branch),
+			})
+		}
+		pbSteps = append(pbSteps, pbStep)
+	}
+	return pbSteps
+}
+
+// sendError - Tells the client why a stage could not run and what kind of error
+// stopped it
+func sendError(stream pb.CorkTypeService_StageExecuteServer, kind string, err error) {
+	sendErrorEvent(stream, &pb.ErrorEvent{
+		Message: err.Error(),
+		Kind:    kind,
+	})
+}
+
+func sendErrorEvent(stream pb.CorkTypeService_StageExecuteServer, errorEvent *pb.ErrorEvent) {
+	stream.Send(&pb.ExecuteOutputEvent{
+		Type: "error",
+		Body: &pb.ExecuteOutputEvent_Error{
+			Error: errorEvent,
+		},
+	})
+}
+
+// stageErrorEvent - Describes the error of a stage that ran. A failed step is
+// reported with its exit code. Anything else is a problem with the infrastructure
+func stageErrorEvent(err error, secrets []string) *pb.ErrorEvent {
+	errorEvent := &pb.ErrorEvent{
+		Message:  capture.RedactString(secrets, err.Error()),
+		Kind:     pb.ErrorKindInfrastructure,
+		ExitCode: -1,
+	}
+
+	// The stage's own failure matters more than the failure of its hooks
+	cause := err
+	if hookErr, ok := err.(executor.HookError); ok {
+		cause = hookErr.Err
+		if cause == nil {
+			cause = hookErr.HookErrors[0]
+		}
+	}
+
+	if failure, ok := cause.(executor.StepFailure); ok {
+		errorEvent.Kind = pb.ErrorKindStep
+		errorEvent.Step = failure.Step
+		if code, ok := failure.ExitCode(); ok {
+			errorEvent.ExitCode = int32(code)
+		}
+	}
+	return errorEvent
+}
+
+// sendEnd - Tells the client that the stage ended and if it succeeded
+func sendEnd(stream pb.CorkTypeService_StageExecuteServer, tags []string, started time.Time, err error, secrets []string) {
+	end := &pb.EndEvent{
+		Tags:       tags,
+		Status:     executor.StageStatus(err),
+		DurationMs: int64(time.Since(started) / time.Millisecond),
+	}
+	if err != nil {
+		end.Error = capture.RedactString(secrets, err.Error())
+	}
+	stream.Send(&pb.ExecuteOutputEvent{
+		Type: "end",
+		Body: &pb.ExecuteOutputEvent_End{
+			End: end,
+		},
+	})
+}
+
 func (c *CorkTypeServer) createTemplateRenderer(params map[string]string) *definition.CorkTemplateRenderer {
 	return definition.NewTemplateRendererWithOptions(definition.CorkTemplateRendererOptions{
-		WorkDir:     c.WorkDir,
-		HostWorkDir: c.HostWorkDir,
-		CacheDir:    c.CacheDir,
-		UserParams:  params,
+		WorkDir:      c.WorkDir,
+		HostWorkDir:  c.HostWorkDir,
+		CacheDir:     c.CacheDir,
+		VolumePrefix: c.VolumePrefix,
+		UserParams:   params,
 	})
 }
 
@@ -163,7 +500,9 @@ func (c *CorkTypeServer) EventReact(ctx context.Context, req *pb.EventReactReque
 	return nil, nil
 }
 
-func (c *CorkTypeServer) Initialize() {
+// RunStartupHook - Runs the type's hooks/startup, if it has one, before the
+// server handles requests
+func (c *CorkTypeServer) RunStartupHook() {
 	log.Debug("Initializing cork-server")
 
 	startupHookPath := path.Join(c.CorkDir, "hooks/startup")
@@ -235,11 +574,12 @@ func (c *CorkTypeServer) StepExecuteAll(req *pb.StepExecuteAllRequest, stream pb
 func newServer(c *cli.Context) (*CorkTypeServer, error) {
 	corkDir := c.String("dir")
 	server := CorkTypeServer{
-		CorkDir:     corkDir,
-		WorkDir:     c.String("work-dir"),
-		HostWorkDir: c.String("host-work-dir"),
-		CacheDir:    c.String("cache-dir"),
-		ProjectName: c.String("project"),
+		CorkDir:      corkDir,
+		WorkDir:      c.String("work-dir"),
+		HostWorkDir:  c.String("host-work-dir"),
+		CacheDir:     c.String("cache-dir"),
+		VolumePrefix: c.String("volume-prefix"),
+		ProjectName:  c.String("project"),
 	}
 	serverDef, err := definition.LoadFromDir(corkDir)
 	if err != nil {
@@ -362,6 +702,11 @@ func init() {
 				Usage:  "The name of the project",
 				EnvVar: "CORK_PROJECT_NAME",
 			},
+			cli.StringFlag{
+				Name:   "volume-prefix",
+				Usage:  "The prefix used to scope named volumes to the project",
+				EnvVar: "CORK_VOLUME_PREFIX",
+			},
 		},
 	})
 }
@@ -375,6 +720,7 @@ func cmdServe(c *cli.Context) error {
 	log.Debugf("HostWorkDir=%s", c.String("host-work-dir"))
 	log.Debugf("CacheDir=%s", c.String("cache-dir"))
 	log.Debugf("ProjectName=%s", c.String("project"))
+	log.Debugf("VolumePrefix=%s", c.String("volume-prefix"))
 
 	// Run startup hooks
 
@@ -388,7 +734,7 @@ func cmdServe(c *cli.Context) error {
 		os.Exit(1)
 		return err
 	}
-	corkTypeServer.Initialize()
+	corkTypeServer.RunStartupHook()
 
 	log.Debugf("Starting cork-server at %d", port)
 	grpcServer := grpc.NewServer()
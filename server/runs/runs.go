@@ -0,0 +1,124 @@
+package runs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+// RunOutputs - The outputs of the steps that completed in a run of a stage
+type RunOutputs struct {
+	RunID   string                       `json:"runId"`
+	Stage   string                       `json:"stage"`
+	Outputs map[string]map[string]string `json:"outputs"`
+}
+
+// RunNotFound - No outputs were saved for a run or a stage
+type RunNotFound struct {
+	Message string
+}
+
+func (rnf RunNotFound) Error() string {
+	return rnf.Message
+}
+
+// IsRunNotFound - Checks if an error is because outputs were never saved
+func IsRunNotFound(err error) bool {
+	_, ok := err.(RunNotFound)
+	return ok
+}
+
+// Store - Keeps the outputs of runs in the cache dir so a later run can resume a stage
+type Store struct {
+	Dir string
+}
+
+func NewStore(cacheDir string) *Store {
+	return &Store{
+		Dir: path.Join(cacheDir, "runs"),
+	}
+}
+
+func (s *Store) outputsPath(runID string) string {
+	return path.Join(s.Dir, runID, "outputs.json")
+}
+
+func (s *Store) latestPath() string {
+	return path.Join(s.Dir, "latest.json")
+}
+
+// Save - Saves the outputs of a run and records it as the latest run of its stage
+func (s *Store) Save(run *RunOutputs) error {
+	if run.RunID == "" {
+		return fmt.Errorf("Cannot save the outputs of a run without an id")
+	}
+	err := os.MkdirAll(path.Dir(s.outputsPath(run.RunID)), 0700)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(s.outputsPath(run.RunID), run)
+	if err != nil {
+		return err
+	}
+
+	latest, err := s.latestRuns()
+	if err != nil {
+		return err
+	}
+	latest[run.Stage] = run.RunID
+	return writeJSON(s.latestPath(), latest)
+}
+
+// Load - Loads the outputs of a run
+func (s *Store) Load(runID string) (*RunOutputs, error) {
+	var run RunOutputs
+	err := readJSON(s.outputsPath(runID), &run)
+	if os.IsNotExist(err) {
+		return nil, RunNotFound{Message: fmt.Sprintf(`No outputs were saved for run "%s"`, runID)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Latest - Loads the outputs of the latest run of a stage
+func (s *Store) Latest(stage string) (*RunOutputs, error) {
+	latest, err := s.latestRuns()
+	if err != nil {
+		return nil, err
+	}
+	runID, ok := latest[stage]
+	if !ok {
+		return nil, RunNotFound{Message: fmt.Sprintf(`Stage "%s" has not run before. Run the whole stage first`, stage)}
+	}
+	return s.Load(runID)
+}
+
+// latestRuns - Maps each stage to the id of its latest run
+func (s *Store) latestRuns() (map[string]string, error) {
+	latest := map[string]string{}
+	err := readJSON(s.latestPath(), &latest)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return latest, nil
+}
+
+func readJSON(jsonPath string, value interface{}) error {
+	jsonBytes, err := ioutil.ReadFile(jsonPath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBytes, value)
+}
+
+func writeJSON(jsonPath string, value interface{}) error {
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(jsonPath, jsonBytes, 0600)
+}
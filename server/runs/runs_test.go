@@ -0,0 +1,59 @@
+package runs_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/virtru/cork/server/runs"
+)
+
+func TestStoreKeepsLatestRunOfEachStage(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "cork-cache")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(cacheDir)
+	store := runs.NewStore(cacheDir)
+
+	_, err = store.Latest("build")
+	assert.True(t, runs.IsRunNotFound(err))
+
+	assert.NoError(t, store.Save(&runs.RunOutputs{
+		RunID:   "run-1",
+		Stage:   "build",
+		Outputs: map[string]map[string]string{"compile": {"artifact": "app-1.tar"}},
+	}))
+	assert.NoError(t, store.Save(&runs.RunOutputs{
+		RunID:   "run-2",
+		Stage:   "build",
+		Outputs: map[string]map[string]string{"compile": {"artifact": "app-2.tar"}},
+	}))
+	assert.NoError(t, store.Save(&runs.RunOutputs{
+		RunID: "run-3",
+		Stage: "test",
+	}))
+
+	latest, err := store.Latest("build")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "run-2", latest.RunID)
+		assert.Equal(t, "app-2.tar", latest.Outputs["compile"]["artifact"])
+	}
+
+	first, err := store.Load("run-1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "app-1.tar", first.Outputs["compile"]["artifact"])
+	}
+
+	_, err = store.Load("run-4")
+	assert.True(t, runs.IsRunNotFound(err))
+
+	// Other problems reading the outputs are not a missing run
+	assert.NoError(t, ioutil.WriteFile(path.Join(store.Dir, "latest.json"), []byte("{"), 0600))
+	_, err = store.Latest("build")
+	if assert.Error(t, err) {
+		assert.False(t, runs.IsRunNotFound(err))
+	}
+}
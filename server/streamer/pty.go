@@ -0,0 +1,79 @@
+package streamer
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	"github.com/kr/pty"
+)
+
+// TerminalSize - The size of a terminal. A zero size means the size is unknown
+type TerminalSize struct {
+	Rows uint16
+	Cols uint16
+}
+
+// IsKnown - Checks if the size was set
+func (ts TerminalSize) IsKnown() bool {
+	return ts.Rows > 0 && ts.Cols > 0
+}
+
+type winsize struct {
+	Rows   uint16
+	Cols   uint16
+	XPixel uint16
+	YPixel uint16
+}
+
+// setsize - Resizes a pty. The vendored pty package can only get the size
+func setsize(pty *os.File, size TerminalSize) error {
+	ws := winsize{
+		Rows: size.Rows,
+		Cols: size.Cols,
+	}
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		pty.Fd(),
+		syscall.TIOCSWINSZ,
+		uintptr(unsafe.Pointer(&ws)),
+	)
+	if errno != 0 {
+		return syscall.Errno(errno)
+	}
+	return nil
+}
+
+// startWithSize - Starts the command like pty.Start but sizes the pty first so
+// the command starts with the right size
+func startWithSize(cmd *exec.Cmd, size TerminalSize) (*os.File, error) {
+	ptyFile, tty, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+
+	if size.IsKnown() {
+		err = setsize(ptyFile, size)
+		if err != nil {
+			ptyFile.Close()
+			return nil, err
+		}
+	}
+
+	cmd.Stdout = tty
+	cmd.Stdin = tty
+	cmd.Stderr = tty
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Setsid = true
+	err = cmd.Start()
+	if err != nil {
+		ptyFile.Close()
+		return nil, err
+	}
+	return ptyFile, nil
+}
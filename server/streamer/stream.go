@@ -0,0 +1,55 @@
+package streamer
+
+import (
+	"sync"
+
+	pb "github.com/virtru/cork/protocol"
+)
+
+// SyncStream - Serializes sends on a stream that is shared by steps running in parallel
+type SyncStream struct {
+	Stream StepStream
+	lock   sync.Mutex
+}
+
+// NewSyncStream - Creates a new SyncStream
+func NewSyncStream(stream StepStream) *SyncStream {
+	return &SyncStream{
+		Stream: stream,
+	}
+}
+
+func (s *SyncStream) Recv() (*pb.ExecuteInputEvent, error) {
+	return s.Stream.Recv()
+}
+
+func (s *SyncStream) Send(event *pb.ExecuteOutputEvent) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Stream.Send(event)
+}
+
+// TaggedStream - Tags output events with the name of the step that produced them
+type TaggedStream struct {
+	Stream StepStream
+	Step   string
+}
+
+// NewTaggedStream - Creates a new TaggedStream
+func NewTaggedStream(stream StepStream, step string) *TaggedStream {
+	return &TaggedStream{
+		Stream: stream,
+		Step:   step,
+	}
+}
+
+func (t *TaggedStream) Recv() (*pb.ExecuteInputEvent, error) {
+	return t.Stream.Recv()
+}
+
+func (t *TaggedStream) Send(event *pb.ExecuteOutputEvent) error {
+	if output := event.GetOutput(); output != nil {
+		output.Step = t.Step
+	}
+	return t.Stream.Send(event)
+}
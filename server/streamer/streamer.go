@@ -4,9 +4,9 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"sync"
 	"syscall"
 
-	"github.com/kr/pty"
 	pb "github.com/virtru/cork/protocol"
 	"github.com/virtru/cork/server/capture"
 )
@@ -17,8 +17,19 @@ type StepStream interface {
 }
 
 type StepStreamer struct {
-	Stream StepStream
-	Pty    *os.File
+	Stream  StepStream
+	Pty     *os.File
+	Secrets []string
+
+	// The pty may be resized before and while the command runs
+	lock sync.Mutex
+	size TerminalSize
+
+	// Input and a kill that arrive before the command starts are applied once it
+	// has started
+	process      *os.Process
+	pendingInput [][]byte
+	killed       bool
 }
 
 func New(stream StepStream) *StepStreamer {
@@ -27,19 +38,108 @@ func New(stream StepStream) *StepStreamer {
 	}
 }
 
+// NewWithSecrets - Creates a streamer that scrubs the secrets from any output
+func NewWithSecrets(stream StepStream, secrets []string) *StepStreamer {
+	return &StepStreamer{
+		Stream:  stream,
+		Secrets: secrets,
+	}
+}
+
+// Write - Writes input to the command's pty. Input written before the command
+// starts is buffered until it does
 func (c *StepStreamer) Write(bytes []byte) error {
-	_, err := c.Pty.Write(bytes)
+	c.lock.Lock()
+	pty := c.Pty
+	if pty == nil {
+		c.pendingInput = append(c.pendingInput, append([]byte{}, bytes...))
+	}
+	c.lock.Unlock()
+	if pty == nil {
+		return nil
+	}
+	_, err := pty.Write(bytes)
 	return err
 }
 
+// Signal - Sends a signal to the command's process group so anything the command
+// spawned gets it too. A command that is killed before it starts is killed as
+// soon as it starts. Other signals are dropped until then
+func (c *StepStreamer) Signal(signal syscall.Signal) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.process == nil {
+		if signal == syscall.SIGKILL {
+			c.killed = true
+		}
+		return nil
+	}
+	return syscall.Kill(-c.process.Pid, signal)
+}
+
+// Resize - Sets the size of the command's pty. A command that hasn't started
+// gets the size when it starts
+func (c *StepStreamer) Resize(size TerminalSize) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !size.IsKnown() {
+		return nil
+	}
+	c.size = size
+	if c.Pty == nil {
+		return nil
+	}
+	return setsize(c.Pty, size)
+}
+
 func (c *StepStreamer) Run(cmd *exec.Cmd) error {
-	pty, err := pty.Start(cmd)
+	c.lock.Lock()
+	size := c.size
+	c.lock.Unlock()
+
+	pty, err := startWithSize(cmd, size)
 	if err != nil {
 		return err
 	}
+
+	// Apply any resize, input or kill that happened while the command started
+	c.lock.Lock()
 	c.Pty = pty
+	c.process = cmd.Process
+	pendingInput := c.pendingInput
+	c.pendingInput = nil
+	if c.killed {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	if c.size != size {
+		err = setsize(pty, c.size)
+	}
+	c.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, input := range pendingInput {
+		_, err = pty.Write(input)
+		if err != nil {
+			return err
+		}
+	}
+
+	outputWriter := c.OutputWriter()
+	_, err = io.Copy(outputWriter, pty)
+	if e, ok := err.(*os.PathError); ok && e.Err == syscall.EIO {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	return outputWriter.Flush()
+}
 
-	stdoutCapture := capture.New(func(p []byte) error {
+// OutputWriter - Creates a writer that sends anything written to it as output events.
+// Flush the writer once the output ends
+func (c *StepStreamer) OutputWriter() *capture.OutputCaptor {
+	return capture.NewRedacting(c.Secrets, func(p []byte) error {
 		current := pb.ExecuteOutputEvent{
 			Type: "output",
 			Body: &pb.ExecuteOutputEvent_Output{
@@ -51,29 +151,9 @@ func (c *StepStreamer) Run(cmd *exec.Cmd) error {
 		}
 		return c.Stream.Send(&current)
 	})
-
-	_, err = io.Copy(stdoutCapture, pty)
-	if e, ok := err.(*os.PathError); ok && e.Err == syscall.EIO {
-		err = nil
-	}
-	if err != nil {
-		return err
-	}
-	return nil
 }
 
+// Close - The end of the stage is sent by the server once every step is done
 func (c *StepStreamer) Close() error {
-	/*
-		final := pb.ExecuteEvent{
-			Type: "end",
-			Body: &pb.ExecuteEvent_Empty{
-				Empty: &pb.Empty{},
-			},
-		}
-		err := c.Stream.Send(&final)
-		if err != nil {
-			return err
-		}
-	*/
 	return nil
 }
@@ -0,0 +1,64 @@
+package streamer_test
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/streamer"
+)
+
+type outputStream struct {
+	output bytes.Buffer
+}
+
+func (s *outputStream) Recv() (*pb.ExecuteInputEvent, error) {
+	return nil, nil
+}
+
+func (s *outputStream) Send(event *pb.ExecuteOutputEvent) error {
+	s.output.Write(event.GetOutput().GetBytes())
+	return nil
+}
+
+func TestStepStreamerStartsWithTerminalSize(t *testing.T) {
+	stream := &outputStream{}
+	stepStreamer := streamer.New(stream)
+	assert.NoError(t, stepStreamer.Resize(streamer.TerminalSize{Rows: 33, Cols: 120}))
+
+	cmd := exec.Command("stty", "size")
+	if !assert.NoError(t, stepStreamer.Run(cmd)) {
+		return
+	}
+	assert.NoError(t, cmd.Wait())
+	assert.Equal(t, "33 120", strings.TrimSpace(stream.output.String()))
+}
+
+func TestStepStreamerBuffersInputBeforeStart(t *testing.T) {
+	stream := &outputStream{}
+	stepStreamer := streamer.New(stream)
+	assert.NoError(t, stepStreamer.Write([]byte("early\n")))
+
+	cmd := exec.Command("sh", "-c", "read line && echo got $line")
+	if !assert.NoError(t, stepStreamer.Run(cmd)) {
+		return
+	}
+	assert.NoError(t, cmd.Wait())
+	assert.Contains(t, stream.output.String(), "got early")
+}
+
+func TestStepStreamerKillBeforeStart(t *testing.T) {
+	stream := &outputStream{}
+	stepStreamer := streamer.New(stream)
+	assert.NoError(t, stepStreamer.Signal(syscall.SIGKILL))
+
+	cmd := exec.Command("sleep", "60")
+	if !assert.NoError(t, stepStreamer.Run(cmd)) {
+		return
+	}
+	assert.Error(t, cmd.Wait())
+}
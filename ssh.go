@@ -12,6 +12,7 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/virtru/cork/client"
 
 	"io"
 
@@ -246,8 +247,14 @@ func (d *DockerSSHCommand) newSession() (*ssh.Session, error) {
 		ssh.TTY_OP_OSPEED: 14400,
 	}
 
+	// Match the host's terminal when there is one
+	rows, cols, err := client.TerminalSize()
+	if err != nil {
+		rows, cols = 40, 80
+	}
+
 	log.Debug("Setting up PTY to get output")
-	if err = session.RequestPty("xterm", 80, 40, modes); err != nil {
+	if err = session.RequestPty("xterm", rows, cols, modes); err != nil {
 		session.Close()
 		return nil, fmt.Errorf("request for pseudo terminal failed: %s", err)
 	}
@@ -301,6 +308,12 @@ func (d *DockerSSHCommand) runCommand(envVars []string) error {
 		go io.Copy(d.Stderr, stderr)
 	}
 
+	done := make(chan bool)
+	defer close(done)
+	client.WatchTerminalSize(done, func(rows int, cols int) {
+		session.WindowChange(rows, cols)
+	})
+
 	return session.Run(d.Command)
 }
 
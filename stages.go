@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	docker "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+	"github.com/virtru/cork/client"
+	pb "github.com/virtru/cork/protocol"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var queryFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:   "force-pull-image",
+		Usage:  "Forces cork to pull the latest version of the cork container",
+		EnvVar: "CORK_FORCE_PULL_IMAGE",
+	},
+	cli.StringFlag{
+		Name:   "ssh-key",
+		Usage:  "The ssh key path to use",
+		EnvVar: "CORK_SSH_KEY",
+	},
+	cli.StringFlag{
+		Name:   "override-cork-server",
+		Usage:  `Path to a directory containing a cork-server to use on the cork type server`,
+		EnvVar: "CORK_OVERRIDE_CORK_SERVER",
+	},
+}
+
+func init() {
+	registerCommand(cli.Command{
+		Name:        "stages",
+		Description: "List the stages of the project's cork type",
+		Action:      cmdStages,
+		Flags:       queryFlags,
+	})
+
+	registerCommand(cli.Command{
+		Name:        "describe",
+		Usage:       "describe <stage>",
+		Description: "Describe the steps and params of a stage",
+		Action:      cmdDescribe,
+		Flags: append([]cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "tag, t",
+				Usage: "Activate a tag to see the steps that run with it",
+			},
+		}, queryFlags...),
+	})
+}
+
+// queryCorkType - Boots the project's cork type container and runs the action against its server
+func queryCorkType(c *cli.Context, action ClientAction) error {
+	log.Debug("Loading cork.yml")
+	corkDef, err := loadCorkYaml()
+	if err != nil {
+		return err
+	}
+	return runCorkType(c, corkDef, action)
+}
+
+// runCorkType - Boots the cork type container of a definition and runs the action against its server
+func runCorkType(c *cli.Context, corkDef *CorkDefinition, action ClientAction) error {
+	control := NewControl()
+	control.HandleTerminate()
+
+	metadata, err := loadCorkProjectMetadata()
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Connecting to docker")
+	dockerClient, err := docker.NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+
+	runner, err := New(dockerClient, control, CorkTypeContainerOptions{
+		ProjectName:               corkDef.Name,
+		CacheVolumeName:           metadata.CacheVolumeName(),
+		VolumePrefix:              metadata.VolumePrefix(),
+		ImageName:                 corkDef.Type,
+		Debug:                     c.GlobalBool("debug"),
+		ForcePullImage:            c.Bool("force-pull-image"),
+		SSHKeyPath:                c.String("ssh-key"),
+		Definition:                corkDef,
+		OverrideCorkServerDirPath: c.String("override-cork-server"),
+	})
+	if err != nil {
+		return err
+	}
+	return runner.Run(action)
+}
+
+func cmdStages(c *cli.Context) error {
+	var stages []*pb.StageSummary
+	err := queryCorkType(c, func(corkClient *client.Client) error {
+		var err error
+		stages, err = corkClient.ListStages()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, stage := range stages {
+		fmt.Fprintf(writer, "%s\t%s\n", stage.GetName(), stage.GetDescription())
+	}
+	return writer.Flush()
+}
+
+func cmdDescribe(c *cli.Context) error {
+	stageName := c.Args().Get(0)
+	if stageName == "" {
+		return fmt.Errorf("stage is required")
+	}
+
+	var description *pb.DescribeStageResponse
+	err := queryCorkType(c, func(corkClient *client.Client) error {
+		var err error
+		description, err = corkClient.DescribeStage(stageName, c.StringSlice("tag"))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	printStageDescription(description)
+	return nil
+}
+
+func printStageDescription(description *pb.DescribeStageResponse) {
+	blue := color.New(color.FgBlue)
+
+	blue.Printf("Stage: ")
+	fmt.Println(description.GetName())
+	if description.GetDescription() != "" {
+		blue.Printf("Description: ")
+		fmt.Println(description.GetDescription())
+	}
+
+	fmt.Println("")
+	blue.Println("Steps:")
+	printSteps(description.GetSteps(), "  ")
+
+	if len(description.GetOnFailure()) > 0 {
+		fmt.Println("")
+		blue.Println("On failure:")
+		printSteps(description.GetOnFailure(), "  ")
+	}
+
+	if len(description.GetFinally()) > 0 {
+		fmt.Println("")
+		blue.Println("Finally:")
+		printSteps(description.GetFinally(), "  ")
+	}
+
+	fmt.Println("")
+	blue.Println("Params:")
+	params := description.GetParams()
+	if len(params) == 0 {
+		fmt.Println("  none")
+		return
+	}
+
+	var paramNames []string
+	for paramName := range params {
+		paramNames = append(paramNames, paramName)
+	}
+	sort.Strings(paramNames)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "  NAME\tTYPE\tDEFAULT\tDESCRIPTION")
+	for _, paramName := range paramNames {
+		param := params[paramName]
+		paramType := param.GetType()
+		if paramType == "" {
+			paramType = "string"
+		}
+		if len(param.GetChoices()) > 0 {
+			paramType = fmt.Sprintf("%s (%s)", paramType, strings.Join(param.GetChoices(), "|"))
+		}
+		paramDefault := ""
+		if param.GetHasDefault() {
+			paramDefault = param.GetDefault()
+		}
+		fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\n", paramName, paramType, paramDefault, param.GetDescription())
+	}
+	writer.Flush()
+}
+
+func printSteps(steps []*pb.Step, indent string) {
+	for i, step := range steps {
+		name := step.GetName()
+		if name == "" {
+			name = "(unnamed)"
+		}
+		fmt.Printf("%s%d. %s %s\n", indent, i+1, name, color.New(color.Faint).Sprintf("[%s]", step.GetType()))
+		if step.GetWhen() != "" {
+			fmt.Printf("%s   when: %s\n", indent, step.GetWhen())
+		}
+		for j, branch := range step.GetBranches() {
+			fmt.Printf("%s   branch %d:\n", indent, j+1)
+			printSteps(branch.GetSteps(), indent+"     ")
+		}
+	}
+}
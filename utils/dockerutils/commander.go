@@ -1,7 +1,11 @@
 package dockerutils
 
 import (
+	"archive/tar"
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"regexp"
 	"strings"
@@ -17,10 +21,11 @@ import (
 
 // DockerCommander - A high level docker interface
 type DockerCommander struct {
-	Client      *docker.Client
-	Container   *docker.Container
-	CloseWaiter docker.CloseWaiter
-	Options     DockerCommanderOptions
+	Client       *docker.Client
+	Container    *docker.Container
+	CloseWaiter  docker.CloseWaiter
+	Options      DockerCommanderOptions
+	imageEnsured bool
 }
 
 type DockerCommanderOptions struct {
@@ -161,6 +166,19 @@ func (dc *DockerCommander) pullImage() error {
 }
 
 func (dc *DockerCommander) ensureImage() error {
+	// The image only needs to be pulled once per commander
+	if dc.imageEnsured {
+		return nil
+	}
+	err := dc.checkImage()
+	if err != nil {
+		return err
+	}
+	dc.imageEnsured = true
+	return nil
+}
+
+func (dc *DockerCommander) checkImage() error {
 	if dc.Options.ForcePullImage {
 		return dc.pullImage()
 	}
@@ -175,6 +193,69 @@ func (dc *DockerCommander) ensureImage() error {
 	return nil
 }
 
+// ImageFileNotFound - A file that was read from an image does not exist
+type ImageFileNotFound struct {
+	Path  string
+	Image string
+}
+
+func (e ImageFileNotFound) Error() string {
+	return fmt.Sprintf("File %s not found in image %s", e.Path, e.Image)
+}
+
+// IsImageFileNotFound - Checks if an error is because a file is missing from an image
+func IsImageFileNotFound(err error) bool {
+	_, ok := err.(ImageFileNotFound)
+	return ok
+}
+
+// ReadImageFile - Reads a file from the image without starting a container
+func (dc *DockerCommander) ReadImageFile(filePath string) ([]byte, error) {
+	err := dc.ensureImage()
+	if err != nil {
+		return nil, err
+	}
+
+	container, err := dc.Client.CreateContainer(docker.CreateContainerOptions{
+		Config: &docker.Config{
+			Image: dc.Options.Image,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer dc.Client.RemoveContainer(docker.RemoveContainerOptions{
+		ID:    container.ID,
+		Force: true,
+	})
+
+	var archive bytes.Buffer
+	err = dc.Client.DownloadFromContainer(container.ID, docker.DownloadFromContainerOptions{
+		Path:         filePath,
+		OutputStream: &archive,
+	})
+	if dockerErr, ok := err.(*docker.Error); ok && dockerErr.Status == http.StatusNotFound {
+		return nil, ImageFileNotFound{Path: filePath, Image: dc.Options.Image}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reader := tar.NewReader(&archive)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, ImageFileNotFound{Path: filePath, Image: dc.Options.Image}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg {
+			return ioutil.ReadAll(reader)
+		}
+	}
+}
+
 func (dc *DockerCommander) createHostConfig() (*docker.HostConfig, error) {
 	config := docker.HostConfig{
 		Binds:      dc.Options.Binds,
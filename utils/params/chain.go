@@ -0,0 +1,166 @@
+package params
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/virtru/cork/client"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/capture"
+)
+
+// NamedParamProvider - A provider in a chain. The name says where its values come from
+type NamedParamProvider struct {
+	Name     string
+	Provider client.ParamProvider
+}
+
+// ChainParamProvider - Asks each of its providers in turn for the params that
+// earlier providers didn't supply. Providers only return the params they have a
+// value for, except the last which is usually the interactive prompt
+type ChainParamProvider struct {
+	Providers []NamedParamProvider
+
+	// The name of the provider that supplied each param
+	Sources map[string]string
+
+	// Explains where each value came from after the params are loaded
+	Explain io.Writer
+}
+
+func NewChainProvider(providers ...NamedParamProvider) *ChainParamProvider {
+	return &ChainParamProvider{
+		Providers: providers,
+		Sources:   map[string]string{},
+	}
+}
+
+func (cpp *ChainParamProvider) LoadParams(paramDefinitions map[string]*pb.ParamDefinition) (map[string]string, error) {
+	resolvedParams := make(map[string]string)
+	remaining := make(map[string]*pb.ParamDefinition)
+	for paramName, paramDefinition := range paramDefinitions {
+		remaining[paramName] = paramDefinition
+	}
+
+	for _, provider := range cpp.Providers {
+		if len(remaining) == 0 {
+			break
+		}
+		providedParams, err := provider.Provider.LoadParams(remaining)
+		if err != nil {
+			return nil, err
+		}
+		for paramName, paramValue := range providedParams {
+			if _, ok := remaining[paramName]; !ok {
+				continue
+			}
+			resolvedParams[paramName] = paramValue
+			cpp.Sources[paramName] = provider.Name
+			delete(remaining, paramName)
+		}
+	}
+
+	if cpp.Explain != nil {
+		err := cpp.explain(cpp.Explain, paramDefinitions, resolvedParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resolvedParams, nil
+}
+
+// ProvidedBy - Keeps the params that one of the named providers supplied
+func (cpp *ChainParamProvider) ProvidedBy(params map[string]string, names ...string) map[string]string {
+	providers := map[string]bool{}
+	for _, name := range names {
+		providers[name] = true
+	}
+	provided := make(map[string]string)
+	for paramName, paramValue := range params {
+		if providers[cpp.Sources[paramName]] {
+			provided[paramName] = paramValue
+		}
+	}
+	return provided
+}
+
+// explain - Writes the value of each param and where it came from. Sensitive
+// values are masked
+func (cpp *ChainParamProvider) explain(writer io.Writer, paramDefinitions map[string]*pb.ParamDefinition, resolvedParams map[string]string) error {
+	var paramNames []string
+	for paramName := range paramDefinitions {
+		paramNames = append(paramNames, paramName)
+	}
+	sort.Strings(paramNames)
+
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "PARAM\tVALUE\tSOURCE")
+	for _, paramName := range paramNames {
+		value := resolvedParams[paramName]
+		if paramDefinitions[paramName].GetIsSensitive() {
+			value = capture.Mask
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\n", paramName, value, cpp.Sources[paramName])
+	}
+	return tabWriter.Flush()
+}
+
+// MapParamProvider - Provides params from a map, like the params of cork.yml or a params file
+type MapParamProvider struct {
+	Params map[string]string
+}
+
+func (mpp *MapParamProvider) LoadParams(paramDefinitions map[string]*pb.ParamDefinition) (map[string]string, error) {
+	resolvedParams := make(map[string]string)
+	for paramName := range paramDefinitions {
+		paramValue, ok := mpp.Params[paramName]
+		if ok {
+			resolvedParams[paramName] = paramValue
+		}
+	}
+	return resolvedParams, nil
+}
+
+// EnvParamProvider - Provides params from CORK_PARAM_<NAME> environment variables
+type EnvParamProvider struct {
+	LookupEnv func(key string) (string, bool)
+}
+
+func NewEnvProvider() *EnvParamProvider {
+	return &EnvParamProvider{
+		LookupEnv: os.LookupEnv,
+	}
+}
+
+// ParamEnvVar - The environment variable of a param
+func ParamEnvVar(paramName string) string {
+	return fmt.Sprintf("CORK_PARAM_%s", strings.ToUpper(strings.Replace(paramName, "-", "_", -1)))
+}
+
+func (epp *EnvParamProvider) LoadParams(paramDefinitions map[string]*pb.ParamDefinition) (map[string]string, error) {
+	resolvedParams := make(map[string]string)
+	for paramName := range paramDefinitions {
+		paramValue, ok := epp.LookupEnv(ParamEnvVar(paramName))
+		if ok {
+			resolvedParams[paramName] = paramValue
+		}
+	}
+	return resolvedParams, nil
+}
+
+// DefaultParamProvider - Provides the defaults of params that have one
+type DefaultParamProvider struct{}
+
+func (dpp *DefaultParamProvider) LoadParams(paramDefinitions map[string]*pb.ParamDefinition) (map[string]string, error) {
+	resolvedParams := make(map[string]string)
+	for paramName, paramDefinition := range paramDefinitions {
+		if paramDefinition.GetHasDefault() {
+			resolvedParams[paramName] = paramDefinition.GetDefault()
+		}
+	}
+	return resolvedParams, nil
+}
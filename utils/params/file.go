@@ -0,0 +1,136 @@
+package params
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadParamsFile - Loads params from a YAML, JSON or dotenv file. The format is
+// chosen by the file's extension. Files without a .yml, .yaml or .json extension
+// are read as dotenv files
+func LoadParamsFile(paramsPath string) (map[string]string, error) {
+	fileBytes, err := ioutil.ReadFile(paramsPath)
+	if err != nil {
+		return nil, fmt.Errorf(`Cannot read params file "%s": %v`, paramsPath, err)
+	}
+
+	var params map[string]string
+	switch strings.ToLower(filepath.Ext(paramsPath)) {
+	case ".yml", ".yaml":
+		params, err = parseYAMLParams(fileBytes)
+	case ".json":
+		params, err = parseJSONParams(fileBytes)
+	default:
+		params, err = parseDotenvParams(fileBytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`Invalid params file "%s": %v`, paramsPath, err)
+	}
+	return params, nil
+}
+
+func parseYAMLParams(fileBytes []byte) (map[string]string, error) {
+	var values map[string]interface{}
+	err := yaml.Unmarshal(fileBytes, &values)
+	if err != nil {
+		return nil, err
+	}
+	return paramValues(values)
+}
+
+func parseJSONParams(fileBytes []byte) (map[string]string, error) {
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(fileBytes))
+	decoder.UseNumber()
+	err := decoder.Decode(&values)
+	if err != nil {
+		return nil, err
+	}
+	return paramValues(values)
+}
+
+// parseDotenvParams - Parses NAME=value lines. Blank lines, comments and an
+// export prefix are ignored. Values may be quoted
+func parseDotenvParams(fileBytes []byte) (map[string]string, error) {
+	params := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		splitLine := strings.SplitN(line, "=", 2)
+		name := strings.TrimSpace(splitLine[0])
+		if len(splitLine) != 2 || name == "" {
+			return nil, fmt.Errorf("line %d must be in the form NAME=value", lineNumber)
+		}
+
+		value := strings.TrimSpace(splitLine[1])
+		if len(value) >= 2 {
+			switch {
+			case value[0] == '"' && value[len(value)-1] == '"':
+				unquoted, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d has an invalid quoted value", lineNumber)
+				}
+				value = unquoted
+			case value[0] == '\'' && value[len(value)-1] == '\'':
+				value = value[1 : len(value)-1]
+			}
+		}
+		params[name] = value
+	}
+	return params, scanner.Err()
+}
+
+// paramValues - Converts values to params. Lists are comma separated like list params
+func paramValues(values map[string]interface{}) (map[string]string, error) {
+	params := map[string]string{}
+	for name, value := range values {
+		if list, ok := value.([]interface{}); ok {
+			var items []string
+			for _, item := range list {
+				itemValue, err := paramValue(name, item)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, itemValue)
+			}
+			params[name] = strings.Join(items, ",")
+			continue
+		}
+
+		paramValue, err := paramValue(name, value)
+		if err != nil {
+			return nil, err
+		}
+		params[name] = paramValue
+	}
+	return params, nil
+}
+
+func paramValue(name string, value interface{}) (string, error) {
+	switch typedValue := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typedValue, nil
+	case bool, int, int64, uint64, json.Number:
+		return fmt.Sprint(typedValue), nil
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf(`param "%s" must be a value or a list of values`, name)
+}
@@ -2,15 +2,23 @@ package params
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/fatih/color"
 	"github.com/segmentio/go-prompt"
+	log "github.com/sirupsen/logrus"
+	"github.com/virtru/cork/client"
 	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/definition"
 )
 
 type InteractiveParamProvider struct {
 	ProvidedParams map[string]string
+
+	// Fails instead of prompting for params without a value or default
+	NonInteractive bool
 }
 
 func NewInteractiveProvider(providedParams map[string]string) *InteractiveParamProvider {
@@ -27,31 +35,88 @@ func (ipp *InteractiveParamProvider) LoadParams(paramDefinitions map[string]*pb.
 	if len(paramDefinitions) == 0 {
 		return resolvedParams, nil
 	}
-	for paramName, paramDefinition := range paramDefinitions {
+
+	var paramNames []string
+	for paramName := range paramDefinitions {
+		paramNames = append(paramNames, paramName)
+	}
+	sort.Strings(paramNames)
+
+	var missingParams []string
+	for _, paramName := range paramNames {
+		paramDefinition := paramDefinitions[paramName]
 		providedParamValue, ok := ipp.ProvidedParams[paramName]
 		if ok {
 			resolvedParams[paramName] = providedParamValue
 			continue
 		}
 
-		var paramValue string
-		fmt.Println("")
 		if paramDefinition.GetHasDefault() {
 			resolvedParams[paramName] = paramDefinition.GetDefault()
 			continue
 		}
 
+		if ipp.NonInteractive {
+			missingParams = append(missingParams, paramName)
+			continue
+		}
+
+		var paramValue string
+		fmt.Println("")
+
 		if paramDefinition.GetDescription() != "" {
 			color.Blue(`Param "%s": %s`, paramName, paramDefinition.GetDescription())
 		}
 
-		if paramDefinition.GetIsSensitive() {
-			paramValue = prompt.PasswordMasked("Input")
-		} else {
-			paramValue = prompt.String("Input")
-		}
+		paramValue = promptParam(paramDefinition)
 		resolvedParams[paramName] = paramValue
 		log.Debugf(`Got input %s="%s"`, paramName, paramValue)
 	}
+
+	if len(missingParams) > 0 {
+		return nil, client.StageError{
+			Message:  fmt.Sprintf("Missing values for params: %s. Set them with --param or --params-file", strings.Join(missingParams, ", ")),
+			Kind:     pb.ErrorKindDefinition,
+			ExitCode: -1,
+		}
+	}
 	return resolvedParams, nil
 }
+
+// promptParam - Prompts for a param using its type. Bools are confirmed, enums are
+// picked from their choices and anything else is asked for until it is valid
+func promptParam(paramDefinition *pb.ParamDefinition) string {
+	switch paramDefinition.GetType() {
+	case "bool":
+		return strconv.FormatBool(prompt.Confirm("Input (yes/no)"))
+	case "enum":
+		choices := paramDefinition.GetChoices()
+		if len(choices) > 0 {
+			return choices[prompt.Choose("Choose", choices)]
+		}
+	}
+
+	param := definition.Param{
+		Type:    paramDefinition.GetType(),
+		Choices: paramDefinition.GetChoices(),
+		Pattern: paramDefinition.GetPattern(),
+	}
+	label := "Input"
+	if param.Type == "list" {
+		label = "Input (comma separated)"
+	}
+	for {
+		var paramValue string
+		if paramDefinition.GetIsSensitive() {
+			paramValue = prompt.PasswordMasked(label)
+		} else {
+			paramValue = prompt.String(label)
+		}
+
+		err := param.ValidateValue(paramValue)
+		if err == nil {
+			return paramValue
+		}
+		color.Red("%v", err)
+	}
+}
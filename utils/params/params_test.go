@@ -0,0 +1,241 @@
+package params_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/virtru/cork/client"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/utils/params"
+	"github.com/virtru/cork/utils/test"
+	"gopkg.in/yaml.v2"
+)
+
+var params_file_yml = `
+environment: staging
+replicas: 3
+debug: true
+regions:
+  - us-east-1
+  - eu-west-1
+`
+
+var params_file_json = `{
+  "environment": "staging",
+  "replicas": 3,
+  "debug": true,
+  "regions": ["us-east-1", "eu-west-1"]
+}`
+
+var params_file_env = `
+# Deployment settings
+environment=staging
+export replicas = 3
+debug="true"
+regions='us-east-1,eu-west-1'
+`
+
+func TestLoadParamsFile(t *testing.T) {
+	tempDir, err := testutils.NewTempDir()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tempDir.Remove()
+
+	expected := map[string]string{
+		"environment": "staging",
+		"replicas":    "3",
+		"debug":       "true",
+		"regions":     "us-east-1,eu-west-1",
+	}
+	files := map[string]string{
+		"params.yml":  params_file_yml,
+		"params.json": params_file_json,
+		".env":        params_file_env,
+	}
+	for fileName, contents := range files {
+		paramsPath := tempDir.InPath(fileName)
+		assert.NoError(t, ioutil.WriteFile(paramsPath, []byte(contents), 0600))
+
+		loaded, err := params.LoadParamsFile(paramsPath)
+		if assert.NoError(t, err, fileName) {
+			assert.Equal(t, expected, loaded, fileName)
+		}
+	}
+
+	badPath := tempDir.InPath("bad.env")
+	assert.NoError(t, ioutil.WriteFile(badPath, []byte("environment\n"), 0600))
+	_, err = params.LoadParamsFile(badPath)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "line 1")
+	}
+
+	_, err = params.LoadParamsFile(tempDir.InPath("missing.yml"))
+	assert.Error(t, err)
+}
+
+func TestNonInteractiveParamsFailFast(t *testing.T) {
+	provider := params.NewInteractiveProvider(map[string]string{
+		"environment": "staging",
+	})
+	provider.NonInteractive = true
+
+	paramDefinitions := map[string]*pb.ParamDefinition{
+		"environment": {},
+		"version":     {},
+		"token":       {IsSensitive: true},
+		"replicas":    {HasDefault: true, Default: "1"},
+	}
+	_, err := provider.LoadParams(paramDefinitions)
+	if stageErr, ok := err.(client.StageError); assert.True(t, ok) {
+		assert.Equal(t, pb.ErrorKindDefinition, stageErr.Kind)
+		assert.Contains(t, stageErr.Message, "token, version")
+	}
+
+	provider.ProvidedParams["version"] = "v1.0.0"
+	provider.ProvidedParams["token"] = "hunter2"
+	loaded, err := provider.LoadParams(paramDefinitions)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1", loaded["replicas"])
+		assert.Equal(t, "v1.0.0", loaded["version"])
+	}
+}
+
+func TestChainParamProvider(t *testing.T) {
+	env := map[string]string{
+		"CORK_PARAM_VERSION": "v2.0.0",
+		"CORK_PARAM_TOKEN":   "from-env",
+	}
+	envProvider := params.NewEnvProvider()
+	envProvider.LookupEnv = func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	interactive := params.NewInteractiveProvider(nil)
+	interactive.NonInteractive = true
+
+	var explanation bytes.Buffer
+	provider := params.NewChainProvider(
+		params.NamedParamProvider{
+			Name:     "--param",
+			Provider: &params.MapParamProvider{Params: map[string]string{"environment": "production"}},
+		},
+		params.NamedParamProvider{Name: "environment", Provider: envProvider},
+		params.NamedParamProvider{
+			Name:     "cork.yml",
+			Provider: &params.MapParamProvider{Params: map[string]string{"environment": "staging", "version": "v1.0.0"}},
+		},
+		params.NamedParamProvider{Name: "default", Provider: &params.DefaultParamProvider{}},
+		params.NamedParamProvider{Name: "prompt", Provider: interactive},
+	)
+	provider.Explain = &explanation
+
+	loaded, err := provider.LoadParams(map[string]*pb.ParamDefinition{
+		"environment": {},
+		"version":     {},
+		"token":       {IsSensitive: true},
+		"replicas":    {HasDefault: true, Default: "1"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]string{
+		"environment": "production",
+		"version":     "v2.0.0",
+		"token":       "from-env",
+		"replicas":    "1",
+	}, loaded)
+	assert.Equal(t, map[string]string{
+		"environment": "--param",
+		"version":     "environment",
+		"token":       "environment",
+		"replicas":    "default",
+	}, provider.Sources)
+
+	assert.Contains(t, explanation.String(), "version")
+	assert.Contains(t, explanation.String(), "******")
+	assert.NotContains(t, explanation.String(), "from-env")
+
+	// Only params from the chosen providers are kept
+	assert.Equal(t, map[string]string{
+		"environment": "production",
+		"replicas":    "1",
+	}, provider.ProvidedBy(loaded, "--param", "default", "prompt"))
+
+	// Params no provider has a value for reach the prompt
+	_, err = provider.LoadParams(map[string]*pb.ParamDefinition{
+		"region": {},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "region")
+	}
+}
+
+var profiles_yml = `
+dev:
+  params:
+    environment: dev
+    region: us-east-1
+ci:
+  inherits: dev
+  params:
+    environment: staging
+    verbose: "true"
+nightly:
+  inherits: ci
+  params:
+    region: eu-west-1
+loop_a:
+  inherits: loop_b
+loop_b:
+  inherits: loop_a
+self:
+  inherits: self
+orphan:
+  inherits: missing
+`
+
+func TestResolveProfile(t *testing.T) {
+	var profiles map[string]*params.Profile
+	if !assert.NoError(t, yaml.Unmarshal([]byte(profiles_yml), &profiles)) {
+		return
+	}
+
+	tests := []struct {
+		profile string
+		params  map[string]string
+		err     string
+	}{
+		{
+			profile: "dev",
+			params:  map[string]string{"environment": "dev", "region": "us-east-1"},
+		},
+		{
+			profile: "ci",
+			params:  map[string]string{"environment": "staging", "region": "us-east-1", "verbose": "true"},
+		},
+		{
+			profile: "nightly",
+			params:  map[string]string{"environment": "staging", "region": "eu-west-1", "verbose": "true"},
+		},
+		{profile: "loop_a", err: `Profile "loop_a" inherits from itself: loop_a -> loop_b -> loop_a`},
+		{profile: "self", err: `Profile "self" inherits from itself: self -> self`},
+		{profile: "orphan", err: `Profile "orphan" inherits from "missing" which is not defined in cork.yml`},
+		{profile: "unknown", err: `Profile "unknown" is not defined in cork.yml`},
+	}
+	for _, test := range tests {
+		resolved, err := params.ResolveProfile(profiles, test.profile)
+		if test.err != "" {
+			if assert.Error(t, err, test.profile) {
+				assert.Equal(t, test.err, err.Error(), test.profile)
+			}
+			continue
+		}
+		if assert.NoError(t, err, test.profile) {
+			assert.Equal(t, test.params, resolved, test.profile)
+		}
+	}
+}
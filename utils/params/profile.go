@@ -0,0 +1,48 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Profile - A named set of params in cork.yml. A profile may inherit the params of
+// another profile and override them
+type Profile struct {
+	Inherits string            `yaml:"inherits,omitempty"`
+	Params   map[string]string `yaml:"params,omitempty"`
+}
+
+// ResolveProfile - Resolves the params of a profile, including the ones it inherits
+func ResolveProfile(profiles map[string]*Profile, profileName string) (map[string]string, error) {
+	var chain []*Profile
+	var names []string
+	visited := map[string]bool{}
+	inheritedBy := ""
+	for name := profileName; name != ""; {
+		if visited[name] {
+			return nil, fmt.Errorf(`Profile "%s" inherits from itself: %s -> %s`, name, strings.Join(names, " -> "), name)
+		}
+		visited[name] = true
+		names = append(names, name)
+
+		profile, ok := profiles[name]
+		if !ok || profile == nil {
+			if inheritedBy == "" {
+				return nil, fmt.Errorf(`Profile "%s" is not defined in cork.yml`, name)
+			}
+			return nil, fmt.Errorf(`Profile "%s" inherits from "%s" which is not defined in cork.yml`, inheritedBy, name)
+		}
+		chain = append(chain, profile)
+		inheritedBy = name
+		name = profile.Inherits
+	}
+
+	// Inherited params are overridden by the profiles that inherit them
+	profileParams := make(map[string]string)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for paramName, paramValue := range chain[i].Params {
+			profileParams[paramName] = paramValue
+		}
+	}
+	return profileParams, nil
+}
@@ -0,0 +1,297 @@
+package runlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/server/capture"
+)
+
+// The statuses of a run
+const (
+	StatusRunning    = "running"
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusTerminated = "terminated"
+)
+
+const (
+	runFileName    = "run.json"
+	outputFileName = "output.jsonl"
+)
+
+// Run - The record of a run of a stage. Sensitive params are masked
+type Run struct {
+	ID         string            `json:"id"`
+	Stage      string            `json:"stage"`
+	Tags       []string          `json:"tags,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt,omitempty"`
+	Steps      []*StepResult     `json:"steps,omitempty"`
+	Exports    map[string]string `json:"exports,omitempty"`
+}
+
+// Duration - How long the run took. Runs that have not finished have no duration
+func (r *Run) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// StepResult - The result of a step of a run
+type StepResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"durationMs"`
+	ExitCode   int32  `json:"exitCode"`
+}
+
+// Output - Output streamed during a run. Output of steps running in parallel is
+// marked so it can be replayed with the name of its step
+type Output struct {
+	Time     time.Time `json:"time"`
+	Step     string    `json:"step,omitempty"`
+	Parallel bool      `json:"parallel,omitempty"`
+	Bytes    []byte    `json:"bytes"`
+}
+
+// Store - Keeps the records of runs in a directory, one directory for each run
+type Store struct {
+	Dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{
+		Dir: dir,
+	}
+}
+
+func (s *Store) runPath(runID string, fileName string) string {
+	return path.Join(s.Dir, runID, fileName)
+}
+
+// Start - Starts recording a run
+func (s *Store) Start(run *Run) (*Recorder, error) {
+	if run.ID == "" {
+		return nil, fmt.Errorf("Cannot record a run without an id")
+	}
+	err := os.MkdirAll(path.Join(s.Dir, run.ID), 0700)
+	if err != nil {
+		return nil, err
+	}
+	output, err := os.OpenFile(s.runPath(run.ID, outputFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Status = StatusRunning
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	recorder := &Recorder{
+		store:   s,
+		run:     run,
+		output:  output,
+		encoder: json.NewEncoder(output),
+	}
+	err = recorder.save()
+	if err != nil {
+		output.Close()
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// Load - Loads the record of a run
+func (s *Store) Load(runID string) (*Run, error) {
+	runBytes, err := ioutil.ReadFile(s.runPath(runID, runFileName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf(`Cannot find run "%s"`, runID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var run Run
+	err = json.Unmarshal(runBytes, &run)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// List - Loads the records of every run, most recent first
+func (s *Store) List() ([]*Run, error) {
+	entries, err := ioutil.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*Run
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := s.Load(entry.Name())
+		if err != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// Latest - Loads the record of the most recent run
+func (s *Store) Latest() (*Run, error) {
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("No runs have been recorded")
+	}
+	return runs[0], nil
+}
+
+// ReadOutput - Reads the output of a run in the order it was streamed
+func (s *Store) ReadOutput(runID string) ([]*Output, error) {
+	outputFile, err := os.Open(s.runPath(runID, outputFileName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf(`Cannot find the output of run "%s"`, runID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer outputFile.Close()
+
+	var outputs []*Output
+	decoder := json.NewDecoder(bufio.NewReader(outputFile))
+	for decoder.More() {
+		var output Output
+		err = decoder.Decode(&output)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, &output)
+	}
+	return outputs, nil
+}
+
+// Recorder - Records a run as its events stream from the cork server
+type Recorder struct {
+	store   *Store
+	run     *Run
+	lock    sync.Mutex
+	output  *os.File
+	encoder *json.Encoder
+
+	// The step that is running when steps run in order
+	currentStep string
+}
+
+// ID - The id of the run being recorded
+func (r *Recorder) ID() string {
+	return r.run.ID
+}
+
+// RecordParams - Records the params of the run. Sensitive values are masked
+func (r *Recorder) RecordParams(params map[string]string, paramDefinitions map[string]*pb.ParamDefinition) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.run.Params = map[string]string{}
+	for name, value := range params {
+		if paramDefinition, ok := paramDefinitions[name]; ok && paramDefinition.GetIsSensitive() {
+			value = capture.Mask
+		}
+		r.run.Params[name] = value
+	}
+	r.save()
+}
+
+// RecordEvent - Records the output of the run, the results of its steps and its exports
+func (r *Recorder) RecordEvent(event *pb.ExecuteOutputEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.run.Status != StatusRunning {
+		return
+	}
+	switch event.GetType() {
+	case "export":
+		export := event.GetExport()
+		if r.run.Exports == nil {
+			r.run.Exports = map[string]string{}
+		}
+		r.run.Exports[export.GetName()] = export.GetValue()
+	case "stepStarted":
+		r.currentStep = event.GetStepStarted().GetName()
+	case "stepFinished":
+		finished := event.GetStepFinished()
+		if finished.GetName() == r.currentStep {
+			r.currentStep = ""
+		}
+		r.run.Steps = append(r.run.Steps, &StepResult{
+			Name:       finished.GetName(),
+			Status:     finished.GetStatus(),
+			DurationMs: finished.GetDurationMs(),
+			ExitCode:   finished.GetExitCode(),
+		})
+	case "output":
+		output := event.GetOutput()
+		if output == nil {
+			return
+		}
+		step := output.GetStep()
+		parallel := step != ""
+		if !parallel {
+			step = r.currentStep
+		}
+		r.encoder.Encode(&Output{
+			Time:     time.Now(),
+			Step:     step,
+			Parallel: parallel,
+			Bytes:    output.GetBytes(),
+		})
+	}
+}
+
+// Finish - Records how the run ended. A run is only finished once
+func (r *Recorder) Finish(status string, err error) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.run.Status != StatusRunning {
+		return nil
+	}
+	r.output.Close()
+
+	r.run.Status = status
+	r.run.FinishedAt = time.Now()
+	if err != nil {
+		r.run.Error = err.Error()
+	}
+	return r.save()
+}
+
+func (r *Recorder) save() error {
+	runBytes, err := json.MarshalIndent(r.run, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.store.runPath(r.run.ID, runFileName), runBytes, 0600)
+}
@@ -0,0 +1,106 @@
+package runlog_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "github.com/virtru/cork/protocol"
+	"github.com/virtru/cork/utils/runlog"
+	"github.com/virtru/cork/utils/test"
+)
+
+func outputEvent(step string, output string) *pb.ExecuteOutputEvent {
+	return &pb.ExecuteOutputEvent{
+		Type: "output",
+		Body: &pb.ExecuteOutputEvent_Output{
+			Output: &pb.OutputEvent{
+				Bytes: []byte(output),
+				Step:  step,
+			},
+		},
+	}
+}
+
+func stepEvent(eventType string, name string) *pb.ExecuteOutputEvent {
+	if eventType == "stepStarted" {
+		return &pb.ExecuteOutputEvent{
+			Type: eventType,
+			Body: &pb.ExecuteOutputEvent_StepStarted{
+				StepStarted: &pb.StepStartedEvent{Name: name},
+			},
+		}
+	}
+	return &pb.ExecuteOutputEvent{
+		Type: eventType,
+		Body: &pb.ExecuteOutputEvent_StepFinished{
+			StepFinished: &pb.StepFinishedEvent{Name: name, Status: "succeeded"},
+		},
+	}
+}
+
+func TestRecordRun(t *testing.T) {
+	tempDir, err := testutils.NewTempDir()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tempDir.Remove()
+	store := runlog.NewStore(tempDir.InPath("runs"))
+
+	recorder, err := store.Start(&runlog.Run{
+		ID:    "run-1",
+		Stage: "build",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	recorder.RecordParams(map[string]string{
+		"version": "v1.0.0",
+		"token":   "hunter2",
+	}, map[string]*pb.ParamDefinition{
+		"version": {},
+		"token":   {IsSensitive: true},
+	})
+	recorder.RecordEvent(outputEvent("", ">>> Running build\n"))
+	recorder.RecordEvent(stepEvent("stepStarted", "compile"))
+	recorder.RecordEvent(outputEvent("", "compiling\n"))
+	recorder.RecordEvent(stepEvent("stepFinished", "compile"))
+	recorder.RecordEvent(outputEvent("lint", "linting\n"))
+	recorder.RecordEvent(&pb.ExecuteOutputEvent{
+		Type: "export",
+		Body: &pb.ExecuteOutputEvent_Export{
+			Export: &pb.ExportEvent{Name: "image", Value: "app:1"},
+		},
+	})
+	assert.NoError(t, recorder.Finish(runlog.StatusFailed, fmt.Errorf("lint failed")))
+
+	// Only the first end is recorded
+	assert.NoError(t, recorder.Finish(runlog.StatusTerminated, nil))
+
+	run, err := store.Latest()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "run-1", run.ID)
+	assert.Equal(t, runlog.StatusFailed, run.Status)
+	assert.Equal(t, "lint failed", run.Error)
+	assert.Equal(t, map[string]string{"version": "v1.0.0", "token": "******"}, run.Params)
+	assert.Equal(t, map[string]string{"image": "app:1"}, run.Exports)
+	if assert.Len(t, run.Steps, 1) {
+		assert.Equal(t, "compile", run.Steps[0].Name)
+	}
+	assert.False(t, run.FinishedAt.IsZero())
+
+	outputs, err := store.ReadOutput("run-1")
+	if assert.NoError(t, err) && assert.Len(t, outputs, 3) {
+		assert.Equal(t, "", outputs[0].Step)
+		assert.Equal(t, "compile", outputs[1].Step)
+		assert.Equal(t, []byte("compiling\n"), outputs[1].Bytes)
+		assert.Equal(t, "lint", outputs[2].Step)
+		assert.True(t, outputs[2].Parallel)
+	}
+
+	_, err = store.Load("run-2")
+	assert.Error(t, err)
+}